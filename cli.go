@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// 命令行模式的退出码
+const (
+	exitOK    = 0 // 导入成功或校验未发现错误
+	exitFail  = 1 // 导入失败或校验发现错误
+	exitUsage = 2 // 参数错误
+
+	exitCanceled = 130 // 按 Ctrl+C 取消导入
+)
+
+// 命令行模式下 --dry-run 最多输出的问题条数，--json 输出不受限制
+const maxPrintedIssues = 50
+
+const cliUsage = `用法:
+  csv2o import [选项]    使用已保存的连接配置导入文件
+  csv2o run 任务文件     执行保存的导入任务(YAML/JSON)
+  csv2o profiles         列出已保存的连接配置
+  csv2o help             显示帮助
+
+不带参数运行时启动图形界面。执行 csv2o import -h 查看导入选项。
+`
+
+// isCLICommand 判断第一个参数是否为命令行子命令，其余参数(如 macOS 传入的 -psn_*)仍启动图形界面
+func isCLICommand(arg string) bool {
+	switch arg {
+	case "import", "run", "profiles", "help", "-h", "-help", "--help":
+		return true
+	}
+	return false
+}
+
+// cliResult --json 输出的导入结果
+type cliResult struct {
+	OK       bool          `json:"ok"`
+	Canceled bool          `json:"canceled,omitempty"`
+	Message  string        `json:"message"`
+	Sheets   []sheetImport `json:"sheets,omitempty"`
+}
+
+// runCLI 执行命令行子命令，args 不含程序名，返回进程退出码。
+// 结果输出到 stdout，进度与错误输出到 stderr
+func runCLI(args []string, stdout, stderr io.Writer) int {
+	switch args[0] {
+	case "import":
+		return runImportCommand(args[1:], stdout, stderr)
+	case "run":
+		return runJobCommand(args[1:], stdout, stderr)
+	case "profiles":
+		return runProfilesCommand(stdout, stderr)
+	}
+	fmt.Fprint(stdout, cliUsage)
+	return exitOK
+}
+
+// runProfilesCommand 列出已保存的连接配置，默认配置以 * 标记
+func runProfilesCommand(stdout, stderr io.Writer) int {
+	profiles, err := NewApp().ListProfiles()
+	if err != nil {
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return exitFail
+	}
+	for _, p := range profiles {
+		mark := " "
+		if p.Default {
+			mark = "*"
+		}
+		fmt.Fprintf(stdout, "%s %s\t%s\n", mark, p.Name, p.Description)
+	}
+	return exitOK
+}
+
+// runImportCommand 按连接配置导入文件，未在命令行指定的导入选项使用连接配置中保存的默认值
+func runImportCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	profile := fs.String("profile", "", "连接配置名称，留空时使用默认配置")
+	table := fs.String("table", "", "目标表名，留空时使用连接配置中保存的表名")
+	file := fs.String("file", "", "要导入的 Excel/CSV 文件(必填)")
+	sheet := fs.String("sheet", "", "工作表名，留空时使用第一个工作表")
+	mode := fs.String("mode", "", "导入模式: append / upsert / truncate / delete / replace")
+	keys := fs.String("keys", "", "upsert 使用的键列，多个用逗号分隔，留空时使用主键")
+	where := fs.String("where", "", "delete 模式下删除旧数据的 WHERE 条件，原样拼接到 DELETE 语句中执行，不能包含 ; -- /*")
+	atomic := fs.Bool("atomic", false, "单事务导入，全部成功才提交")
+	maxErrors := fs.Int("max-errors", 0, "允许跳过的出错行数，-1 不限制")
+	truncate := fs.Bool("truncate", false, "超长字符串按列长度截断")
+	dryRun := fs.Bool("dry-run", false, "只校验数据，不写入数据库")
+	jsonOut := fs.Bool("json", false, "以 JSON 格式输出结果")
+	passwordEnv := fs.String("password-env", "CSV2O_PASSWORD", "从该环境变量读取数据库密码，设置后优先于连接配置中保存的密码")
+	passphraseEnv := fs.String("passphrase-env", "CSV2O_PASSPHRASE", "密码使用主密码加密时从该环境变量读取主密码")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "用法: csv2o import --file 文件 [选项]\n\n")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "错误: 无法识别的参数 %s\n", strings.Join(fs.Args(), " "))
+		return exitUsage
+	}
+	if strings.TrimSpace(*file) == "" {
+		fmt.Fprintln(stderr, "错误: 请通过 --file 指定要导入的文件")
+		return exitUsage
+	}
+	if err := checkMaxErrors(*maxErrors); err != nil {
+		fmt.Fprintf(stderr, "错误: --max-errors: %v\n", err)
+		return exitUsage
+	}
+
+	app := newCLIApp(stderr)
+	cfg, err := loadCLIProfile(app, *profile, *passwordEnv, *passphraseEnv)
+	if err != nil {
+		return cliFail(stdout, stderr, *jsonOut, err.Error())
+	}
+
+	opts := cfg.ImportDefaults
+	opts.Mappings = nil
+	run := cliImport{cfg: cfg, table: cfg.TableName, file: *file, truncate: cfg.TruncateChars == "true", dryRun: *dryRun, jsonOut: *jsonOut}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "table":
+			run.table = *table
+		case "sheet":
+			opts.SheetName = *sheet
+		case "mode":
+			opts.LoadMode = *mode
+		case "keys":
+			opts.KeyColumns = nil
+			for _, k := range strings.Split(*keys, ",") {
+				if k = strings.TrimSpace(k); k != "" {
+					opts.KeyColumns = append(opts.KeyColumns, k)
+				}
+			}
+		case "where":
+			opts.DeleteWhere = *where
+		case "atomic":
+			opts.Atomic = *atomic
+		case "max-errors":
+			opts.MaxErrors = *maxErrors
+		case "truncate":
+			run.truncate = *truncate
+		}
+	})
+	run.opts = opts
+	if strings.TrimSpace(run.table) == "" {
+		fmt.Fprintln(stderr, "错误: 请通过 --table 指定目标表")
+		return exitUsage
+	}
+	if _, err := normalizeLoadMode(opts.LoadMode); err != nil {
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return exitUsage
+	}
+	// 连接配置中保存的默认值同样需要校验
+	if err := checkMaxErrors(opts.MaxErrors); err != nil {
+		fmt.Fprintf(stderr, "错误: %v\n", err)
+		return exitUsage
+	}
+	return run.execute(app, stdout, stderr)
+}
+
+// runJobCommand 执行任务文件，--profile、--file 可以覆盖任务中的连接配置与数据文件
+func runJobCommand(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	profile := fs.String("profile", "", "连接配置名称，覆盖任务文件中的 profile")
+	file := fs.String("file", "", "要导入的文件，覆盖任务文件中的 source.file")
+	dryRun := fs.Bool("dry-run", false, "只校验数据，不写入数据库")
+	jsonOut := fs.Bool("json", false, "以 JSON 格式输出结果")
+	passwordEnv := fs.String("password-env", "CSV2O_PASSWORD", "从该环境变量读取数据库密码，设置后优先于连接配置中保存的密码")
+	passphraseEnv := fs.String("passphrase-env", "CSV2O_PASSPHRASE", "密码使用主密码加密时从该环境变量读取主密码")
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "用法: csv2o run 任务文件 [选项]\n\n")
+		fs.PrintDefaults()
+	}
+	// 任务文件可以写在选项之前或之后
+	var jobPath string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		jobPath, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if jobPath == "" && fs.NArg() > 0 {
+		jobPath = fs.Arg(0)
+	} else if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "错误: 无法识别的参数 %s\n", strings.Join(fs.Args(), " "))
+		return exitUsage
+	}
+	if jobPath == "" {
+		fs.Usage()
+		return exitUsage
+	}
+
+	job, err := loadJob(jobPath)
+	if err != nil {
+		return cliFail(stdout, stderr, *jsonOut, err.Error())
+	}
+	if *profile == "" {
+		*profile = job.Profile
+	}
+	if *file == "" {
+		if *file, err = resolveJobFile(jobPath, job.Source.File); err != nil {
+			return cliFail(stdout, stderr, *jsonOut, err.Error())
+		}
+	}
+	fmt.Fprintf(stderr, "任务 %s: 导入文件 %s\n", jobPath, *file)
+
+	app := newCLIApp(stderr)
+	cfg, err := loadCLIProfile(app, *profile, *passwordEnv, *passphraseEnv)
+	if err != nil {
+		return cliFail(stdout, stderr, *jsonOut, err.Error())
+	}
+	run := cliImport{cfg: cfg, table: job.Table, file: *file, truncate: job.Load.Truncate, opts: job.options(), dryRun: *dryRun, jsonOut: *jsonOut}
+	return run.execute(app, stdout, stderr)
+}
+
+// newCLIApp 创建命令行模式使用的 App，进度输出到 stderr
+func newCLIApp(stderr io.Writer) *App {
+	app := NewApp()
+	app.progress = func(percent int, text string) {
+		fmt.Fprintf(stderr, "[%3d%%] %s\n", percent, text)
+	}
+	return app
+}
+
+// cliFail 输出失败信息，--json 时输出到 stdout 的结果中
+func cliFail(stdout, stderr io.Writer, jsonOut bool, msg string) int {
+	if jsonOut {
+		writeJSON(stdout, cliResult{Message: msg})
+	} else {
+		fmt.Fprintf(stderr, "错误: %s\n", msg)
+	}
+	return exitFail
+}
+
+// cliImport 一次命令行导入或校验
+type cliImport struct {
+	cfg      DBConfig
+	table    string
+	file     string
+	truncate bool
+	opts     ImportOptions
+	dryRun   bool
+	jsonOut  bool
+}
+
+// execute 连接数据库执行导入(或 --dry-run 校验)并输出结果，返回退出码
+func (r cliImport) execute(app *App, stdout, stderr io.Writer) int {
+	cfg := r.cfg
+	serviceName := profileServiceName(cfg)
+
+	if r.dryRun {
+		report, err := app.ValidateImport(cfg.DbType, cfg.Host, cfg.Port, cfg.Username, cfg.Password, r.table, r.file,
+			cfg.ConnectionType, serviceName, cfg.TnsConnection, fmt.Sprint(r.truncate), r.opts)
+		if err != nil {
+			return cliFail(stdout, stderr, r.jsonOut, err.Error())
+		}
+		if r.jsonOut {
+			writeJSON(stdout, report)
+		} else {
+			printValidationReport(stdout, report)
+		}
+		if report.ErrorCount > 0 {
+			return exitFail
+		}
+		return exitOK
+	}
+
+	d, err := lookupDialect(cfg.DbType)
+	if err != nil {
+		return cliFail(stdout, stderr, r.jsonOut, err.Error())
+	}
+
+	// Ctrl+C 取消导入并回滚当前批次，而不是直接结束进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	db, err := connectDatabase(ctx, cfg.DbType, cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.ConnectionType, serviceName, cfg.TnsConnection)
+	if err != nil {
+		if ctx.Err() != nil {
+			if r.jsonOut {
+				writeJSON(stdout, cliResult{Canceled: true, Message: canceledMessage(0)})
+			} else {
+				fmt.Fprintln(stderr, canceledMessage(0))
+			}
+			return exitCanceled
+		}
+		return cliFail(stdout, stderr, r.jsonOut, fmt.Sprintf("数据库连接失败: %v", err))
+	}
+	defer db.Close()
+
+	sheets, message, err := app.importFile(ctx, db, d, r.table, r.file, r.truncate, r.opts)
+	result := cliResult{OK: err == nil, Canceled: err != nil && ctx.Err() != nil, Message: message, Sheets: sheets}
+	if err != nil {
+		result.Message = err.Error()
+	}
+	if r.jsonOut {
+		writeJSON(stdout, result)
+	} else if err != nil {
+		fmt.Fprintln(stderr, result.Message)
+	} else {
+		fmt.Fprintln(stdout, result.Message)
+	}
+	if result.Canceled {
+		return exitCanceled
+	}
+	if err != nil {
+		return exitFail
+	}
+	return exitOK
+}
+
+// loadCLIProfile 读取连接配置并准备好数据库密码。
+// 环境变量 passwordEnv 有值时优先使用；密码以主密码加密时从 passphraseEnv 读取主密码解密
+func loadCLIProfile(app *App, name, passwordEnv, passphraseEnv string) (DBConfig, error) {
+	cfg, err := app.LoadProfile(name)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.DbType == "" {
+		return cfg, fmt.Errorf("尚未保存任何连接配置，请先在图形界面中保存")
+	}
+	if password, ok := os.LookupEnv(passwordEnv); ok {
+		cfg.Password = password
+		return cfg, nil
+	}
+	if cfg.PasswordLocked {
+		passphrase := os.Getenv(passphraseEnv)
+		if passphrase == "" {
+			return cfg, fmt.Errorf("连接配置的密码使用主密码加密，请通过环境变量 %s 提供主密码", passphraseEnv)
+		}
+		return app.UnlockProfile(name, passphrase)
+	}
+	return cfg, nil
+}
+
+// printValidationReport 以文本形式输出校验结果
+func printValidationReport(w io.Writer, r ValidationReport) {
+	fmt.Fprintf(w, "校验完成: 共 %d 行，%d 行有错误，错误 %d 个，警告 %d 个\n", r.TotalRows, r.ErrorRows, r.ErrorCount, r.WarningCount)
+	for i, issue := range r.Issues {
+		if i == maxPrintedIssues {
+			fmt.Fprintf(w, "... 其余 %d 个问题未显示，使用 --json 查看全部\n", len(r.Issues)-i)
+			break
+		}
+		var where []string
+		if issue.Sheet != "" {
+			where = append(where, "工作表["+issue.Sheet+"]")
+		}
+		if issue.Row > 0 {
+			where = append(where, fmt.Sprintf("第%d行", issue.Row))
+		}
+		if issue.Column != "" {
+			where = append(where, "列["+issue.Column+"]")
+		}
+		fmt.Fprintf(w, "%-7s %s: %s\n", issue.Level, strings.Join(where, " "), issue.Message)
+	}
+	if r.Truncated {
+		fmt.Fprintln(w, "问题过多，只列出了前一部分")
+	}
+}
+
+// writeJSON 将结果以缩进的 JSON 写出
+func writeJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
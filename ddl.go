@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 推断列类型时最多读取的数据行数
+const ddlSampleRows = 1000
+
+var (
+	integerPattern = regexp.MustCompile(`^[+-]?\d+$`)
+	decimalPattern = regexp.MustCompile(`^[+-]?\d*\.\d+$`)
+)
+
+// 常见的 Oracle/MySQL 保留字，清理后的标识符与之相同时追加下划线；
+// PostgreSQL 与 SQL Server 另有各自的保留字，见 pgReservedWords 与 mssqlReservedWords
+var reservedWords = map[string]bool{
+	"ACCESS": true, "ADD": true, "ALL": true, "ALTER": true, "AND": true, "ANY": true, "AS": true, "ASC": true,
+	"AUDIT": true, "BETWEEN": true, "BIGINT": true, "BY": true, "CASE": true, "CHAR": true, "CHECK": true,
+	"CLUSTER": true, "COLUMN": true, "COMMENT": true, "COMPRESS": true, "CONNECT": true, "CREATE": true,
+	"CROSS": true, "CURRENT": true, "DATE": true, "DECIMAL": true, "DEFAULT": true, "DELETE": true, "DESC": true,
+	"DISTINCT": true, "DOUBLE": true, "DROP": true, "ELSE": true, "EXCLUSIVE": true, "EXISTS": true, "FILE": true,
+	"FLOAT": true, "FOR": true, "FOREIGN": true, "FROM": true, "GRANT": true, "GROUP": true, "HAVING": true,
+	"IDENTIFIED": true, "IMMEDIATE": true, "IN": true, "INCREMENT": true, "INDEX": true, "INITIAL": true,
+	"INNER": true, "INSERT": true, "INT": true, "INTEGER": true, "INTERSECT": true, "INTERVAL": true, "INTO": true,
+	"IS": true, "JOIN": true, "KEY": true, "LEFT": true, "LEVEL": true, "LIKE": true, "LIMIT": true, "LOCK": true,
+	"LONG": true, "MAXEXTENTS": true, "MINUS": true, "MODE": true, "MODIFY": true, "NOT": true, "NOWAIT": true,
+	"NULL": true, "NUMBER": true, "OF": true, "OFFLINE": true, "ON": true, "ONLINE": true, "OPTION": true,
+	"OR": true, "ORDER": true, "OUTER": true, "PCTFREE": true, "PRIMARY": true, "PRIOR": true, "PUBLIC": true,
+	"RANGE": true, "RAW": true, "READ": true, "REFERENCES": true, "RENAME": true, "REPLACE": true,
+	"RESOURCE": true, "REVOKE": true, "RIGHT": true, "ROW": true, "ROWID": true, "ROWNUM": true, "ROWS": true,
+	"SCHEMA": true, "SELECT": true, "SESSION": true, "SET": true, "SHARE": true, "SHOW": true, "SIZE": true,
+	"SMALLINT": true, "START": true, "SYNONYM": true, "SYSDATE": true, "TABLE": true, "THEN": true, "TO": true,
+	"TRIGGER": true, "UID": true, "UNION": true, "UNIQUE": true, "UPDATE": true, "USE": true, "USER": true,
+	"VALIDATE": true, "VALUES": true, "VARCHAR": true, "VARCHAR2": true, "VIEW": true, "WHEN": true,
+	"WHENEVER": true, "WHERE": true, "WITH": true,
+}
+
+// InferredColumn 根据文件内容推断出的列定义
+type InferredColumn struct {
+	Header string `json:"header"` // 文件中的列名
+	Name   string `json:"name"`   // 清理后的数据库列名
+	Type   string `json:"type"`
+}
+
+// TableDDL 推断出的建表语句
+type TableDDL struct {
+	Table      string           `json:"table"`
+	Columns    []InferredColumn `json:"columns"`
+	DDL        string           `json:"ddl"`
+	SampleRows int              `json:"sampleRows"` // 实际用于推断的行数
+}
+
+// columnStats 统计一列样本数据的特征
+type columnStats struct {
+	nonEmpty  int
+	integers  int
+	decimals  int
+	dates     int
+	bools     int
+	hasTime   bool
+	leadZero  bool // 形如 007 的编号，按数字存储会丢失前导零
+	intDigits int
+	scale     int
+	maxChars  int
+	maxBytes  int
+}
+
+func (s *columnStats) add(val string) {
+	if val == "" {
+		return
+	}
+	s.nonEmpty++
+	if n := utf8.RuneCountInString(val); n > s.maxChars {
+		s.maxChars = n
+	}
+	if len(val) > s.maxBytes {
+		s.maxBytes = len(val)
+	}
+
+	digits := strings.TrimLeft(val, "+-")
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	switch {
+	case integerPattern.MatchString(val):
+		s.integers++
+		if len(intPart) > 1 && intPart[0] == '0' {
+			s.leadZero = true
+		}
+	case decimalPattern.MatchString(val):
+		s.decimals++
+		if len(fracPart) > s.scale {
+			s.scale = len(fracPart)
+		}
+	case strings.EqualFold(val, "true") || strings.EqualFold(val, "false"):
+		s.bools++
+		return
+	default:
+		if t, err := tryParseDate(val); err == nil {
+			s.dates++
+			if t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 {
+				s.hasTime = true
+			}
+		}
+		return
+	}
+	if n := len(strings.TrimLeft(intPart, "0")); n > s.intDigits {
+		s.intDigits = n
+	}
+}
+
+// kind 按统计结果判断列的数据类别，全为空或无法归类时按字符串处理。
+// 带前导零的编号按数字存储会丢失前导零，同样按字符串处理
+func (s *columnStats) kind() string {
+	switch {
+	case s.nonEmpty == 0:
+		// 样本中全为空，无法判断类型
+	case s.integers == s.nonEmpty && !s.leadZero:
+		return kindInteger
+	case s.integers+s.decimals == s.nonEmpty && !s.leadZero:
+		return kindDecimal
+	case s.bools == s.nonEmpty:
+		return kindBoolean
+	case s.dates == s.nonEmpty:
+		return kindDate
+	}
+	return kindString
+}
+
+// precision 返回定点数的精度，整数部分留出两位余量
+func (s *columnStats) precision() int {
+	return max(s.intDigits+2, 10) + s.scale
+}
+
+// roundUpLength 在样本最大长度上留出一半余量，再放大到常用的档位
+func roundUpLength(n int) int {
+	want := max(n+n/2, 1)
+	for _, size := range []int{10, 20, 50, 100, 255, 500, 1000, 2000, 4000} {
+		if want <= size {
+			return size
+		}
+	}
+	return want
+}
+
+// sanitizeIdentifier 将文件中的列名或文件名转换为合法的标识符：
+// 非字母数字替换为下划线，不以字母开头时加前缀，避开保留字，并按数据库的长度上限截断
+func sanitizeIdentifier(d dialect, name string) string {
+	var sb strings.Builder
+	lastUnderscore := false
+	for _, r := range strings.TrimSpace(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			lastUnderscore = false
+		} else if !lastUnderscore {
+			sb.WriteByte('_')
+			lastUnderscore = true
+		}
+	}
+	id := strings.Trim(sb.String(), "_")
+	if id == "" {
+		return ""
+	}
+	if first, _ := utf8.DecodeRuneInString(id); !unicode.IsLetter(first) {
+		id = "C_" + id
+	}
+
+	// Oracle 将未加引号的标识符转为大写，PostgreSQL 转为小写
+	id = d.foldIdentifier(id)
+	if d.isReserved(id) {
+		id += "_"
+	}
+	return truncateIdentifier(d, id, "")
+}
+
+// sanitizeTableName 清理用户输入的表名，schema.表名 形式的名称分别清理两部分，
+// 使表建在指定的 schema 中；无法按 schema.表名 拆分时整体作为表名清理
+func sanitizeTableName(d dialect, name string) string {
+	schema, table, err := splitTableName(d, name)
+	if err != nil || schema == "" {
+		return sanitizeIdentifier(d, name)
+	}
+	schema, table = sanitizeIdentifier(d, schema), sanitizeIdentifier(d, table)
+	if schema == "" || table == "" {
+		return sanitizeIdentifier(d, name)
+	}
+	return schema + "." + table
+}
+
+// truncateIdentifier 按数据库的标识符长度上限截断(Oracle 30 字节、PostgreSQL 63 字节、MySQL 64 字符、SQL Server 128 字符)，
+// 并保证 suffix 完整保留在末尾
+func truncateIdentifier(d dialect, id, suffix string) string {
+	limit, inBytes := d.identifierLimit()
+	if inBytes {
+		for len(id)+len(suffix) > limit {
+			_, size := utf8.DecodeLastRuneInString(id)
+			id = id[:len(id)-size]
+		}
+		return id + suffix
+	}
+	if r := []rune(id); len(r)+utf8.RuneCountInString(suffix) > limit {
+		return string(r[:limit-utf8.RuneCountInString(suffix)]) + suffix
+	}
+	return id + suffix
+}
+
+// InferTableDDL samples the file and proposes a CREATE TABLE statement for tableName
+// (or a name derived from the file name) in the dialect of dbType.
+func (a *App) InferTableDDL(dbType, tableName, filePath string, opts ImportOptions) (TableDDL, error) {
+	var result TableDDL
+	d, err := lookupDialect(dbType)
+	if err != nil {
+		return result, err
+	}
+
+	reader, err := openTableReader(filePath, opts)
+	if err != nil {
+		return result, err
+	}
+	defer reader.Close()
+
+	headers := reader.Headers()
+	stats := make([]columnStats, len(headers))
+	for result.SampleRows < ddlSampleRows && reader.Next() {
+		row := reader.Row()
+		for i := range headers {
+			if i < len(row) {
+				stats[i].add(strings.TrimSpace(row[i]))
+			}
+		}
+		result.SampleRows++
+	}
+	if err := reader.Err(); err != nil {
+		return result, err
+	}
+
+	result.Table = sanitizeTableName(d, tableName)
+	if result.Table == "" {
+		result.Table = sanitizeIdentifier(d, strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)))
+	}
+	if result.Table == "" {
+		result.Table = sanitizeIdentifier(d, "IMPORTED_TABLE")
+	}
+
+	used := make(map[string]bool)
+	var defs []string
+	for i, header := range headers {
+		name := sanitizeIdentifier(d, header)
+		if name == "" {
+			name = sanitizeIdentifier(d, fmt.Sprintf("COL_%d", i+1))
+		}
+		// 清理后重名的列追加序号
+		base := name
+		for n := 2; used[strings.ToUpper(name)]; n++ {
+			name = truncateIdentifier(d, base, fmt.Sprintf("_%d", n))
+		}
+		used[strings.ToUpper(name)] = true
+
+		col := InferredColumn{Header: header, Name: name, Type: d.inferType(&stats[i])}
+		result.Columns = append(result.Columns, col)
+		defs = append(defs, fmt.Sprintf("    %s %s", col.Name, col.Type))
+	}
+
+	result.DDL = fmt.Sprintf("CREATE TABLE %s (\n%s\n)", result.Table, strings.Join(defs, ",\n")) + d.createTableSuffix()
+	return result, nil
+}
+
+// ExecuteDDL runs a single CREATE TABLE statement reviewed by the user.
+func (a *App) ExecuteDDL(dbType, host, port, username, password, connectionType, serviceName, tnsConnection, ddl string) string {
+	stmt := strings.TrimSpace(ddl)
+	stmt = strings.TrimSpace(strings.TrimRight(stmt, ";"))
+	fields := strings.Fields(strings.ToUpper(stmt))
+	if len(fields) < 2 || fields[0] != "CREATE" || fields[1] != "TABLE" {
+		return "错误: 只能执行 CREATE TABLE 语句"
+	}
+	if strings.Contains(stmt, ";") {
+		return "错误: 一次只能执行一条语句"
+	}
+
+	db, err := connectDatabase(context.Background(), dbType, host, port, username, password, connectionType, serviceName, tnsConnection)
+	if err != nil {
+		return "错误: 数据库连接失败: " + err.Error()
+	}
+	defer db.Close()
+
+	if _, err := db.Exec(stmt); err != nil {
+		return "错误: 建表失败: " + err.Error()
+	}
+	return "建表成功"
+}
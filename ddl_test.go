@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestSanitizeTableName(t *testing.T) {
+	tests := []struct {
+		dbType string
+		input  string
+		want   string
+	}{
+		{dbType: "oracle", input: "scott.order", want: "SCOTT.ORDER_"},
+		{dbType: "postgres", input: "Sales.User", want: "sales.user_"},
+		{dbType: "sqlserver", input: "dbo.Order Details", want: "dbo_Order_Details"},
+		{dbType: "sqlserver", input: "dbo.[Order Details]", want: "dbo.Order_Details"},
+		{dbType: "mysql", input: "销售 数据", want: "销售_数据"},
+		{dbType: "mysql", input: "2024", want: "C_2024"},
+	}
+	for _, tt := range tests {
+		d, err := lookupDialect(tt.dbType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sanitizeTableName(d, tt.input); got != tt.want {
+			t.Errorf("%s %q = %q，应为 %q", tt.dbType, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeIdentifier(t *testing.T) {
+	tests := []struct {
+		dbType string
+		input  string
+		want   string
+	}{
+		{dbType: "oracle", input: " 订单 编号 ", want: "订单_编号"},
+		{dbType: "oracle", input: "date", want: "DATE_"},
+		{dbType: "mysql", input: "Order", want: "Order_"},
+		{dbType: "mysql", input: "a--b..c", want: "a_b_c"},
+		{dbType: "postgres", input: "Analyze", want: "analyze_"},
+		{dbType: "sqlserver", input: "Backup", want: "Backup_"},
+		{dbType: "mysql", input: "Backup", want: "Backup"},
+		{dbType: "mysql", input: "--", want: ""},
+		{dbType: "oracle", input: "a_very_long_column_name_over_thirty_bytes", want: "A_VERY_LONG_COLUMN_NAME_OVER_T"},
+	}
+	for _, tt := range tests {
+		d, err := lookupDialect(tt.dbType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := sanitizeIdentifier(d, tt.input); got != tt.want {
+			t.Errorf("%s %q = %q，应为 %q", tt.dbType, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestInferType(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		oracle string
+		mysql  string
+	}{
+		{name: "整数", values: []string{"1", "-25", "", "300"}, oracle: "NUMBER(10)", mysql: "INT"},
+		{name: "长整数", values: []string{"12345678901"}, oracle: "NUMBER(19)", mysql: "BIGINT"},
+		{name: "小数", values: []string{"1.5", "20", "-3.125"}, oracle: "NUMBER(13,3)", mysql: "DECIMAL(13,3)"},
+		{name: "前导零的编号", values: []string{"007", "123"}, oracle: "VARCHAR2(10 CHAR)", mysql: "VARCHAR(10)"},
+		{name: "日期", values: []string{"2024-01-31", "2024/02/01"}, oracle: "DATE", mysql: "DATE"},
+		{name: "日期时间", values: []string{"2024-01-31 08:30:00", "2024-02-01"}, oracle: "TIMESTAMP", mysql: "DATETIME"},
+		{name: "字符串", values: []string{"北京", "上海市浦东新区张江高科"}, oracle: "VARCHAR2(20 CHAR)", mysql: "VARCHAR(20)"},
+		{name: "全为空", values: []string{"", ""}, oracle: "VARCHAR2(10 CHAR)", mysql: "VARCHAR(10)"},
+	}
+	for _, tt := range tests {
+		var s columnStats
+		for _, v := range tt.values {
+			s.add(v)
+		}
+		if got := dialects["oracle"].inferType(&s); got != tt.oracle {
+			t.Errorf("%s: Oracle 类型 = %s，应为 %s", tt.name, got, tt.oracle)
+		}
+		if got := dialects["mysql"].inferType(&s); got != tt.mysql {
+			t.Errorf("%s: MySQL 类型 = %s，应为 %s", tt.name, got, tt.mysql)
+		}
+	}
+}
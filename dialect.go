@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// connParams 建立连接所需的参数，各字段的含义由具体数据库解释：
+// ServiceName 对 MySQL/PostgreSQL/SQL Server 为数据库名，对 SQLite 为数据库文件路径；
+// ConnectionType 对 Oracle 为连接方式，对 PostgreSQL 为 sslmode，对 SQL Server 为连接选项
+type connParams struct {
+	Host           string
+	Port           string
+	Username       string
+	Password       string
+	ConnectionType string
+	ServiceName    string
+	TnsConnection  string
+}
+
+// tableRef 解析后的目标表。Schema 对 Oracle 为 OWNER，对 MySQL 为数据库名，
+// 对 PostgreSQL/SQL Server 为 schema，对 SQLite 为 main 或附加的数据库名
+type tableRef struct {
+	Schema string
+	Name   string
+}
+
+// String 返回用于提示信息的 schema.table
+func (t tableRef) String() string {
+	return t.Schema + "." + t.Name
+}
+
+// args 返回按 (schema, 表名) 顺序绑定的查询参数
+func (t tableRef) args() []interface{} {
+	return []interface{}{t.Schema, t.Name}
+}
+
+// qualifiedName 返回加引号后可直接写入 SQL 的 schema.table
+func qualifiedName(d dialect, t tableRef) string {
+	if t.Schema == "" {
+		return d.quote(t.Name)
+	}
+	return d.quote(t.Schema) + "." + d.quote(t.Name)
+}
+
+// identifierQuotes 各数据库用于标识符的引号，表名中出现这些字符时拒绝导入
+const identifierQuotes = "\"`[]"
+
+// splitTableName 将用户输入的 表名 或 schema.表名 拆分为两部分。
+// 带引号(双引号、反引号或方括号)的部分去掉引号后原样保留，其余部分按数据库的大小写规则转换。
+// 未加引号的部分只能包含字母、数字、_、$ 与 #，任何部分都不能包含引号字符，
+// 保证拆分结果只是名称本身，不会混入其他 SQL
+func splitTableName(d dialect, input string) (string, string, error) {
+	var parts []string
+	var cur strings.Builder
+	var closing rune
+	quoted, closed := false, false
+	flush := func() error {
+		part := strings.TrimSpace(cur.String())
+		if !quoted {
+			for _, r := range part {
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' && r != '#' {
+					return fmt.Errorf("表名 [%s] 含有非法字符 %q，包含空格或特殊字符的名称请加引号", input, r)
+				}
+			}
+			part = d.foldIdentifier(part)
+		}
+		parts = append(parts, part)
+		cur.Reset()
+		quoted, closed = false, false
+		return nil
+	}
+	for _, r := range strings.TrimSpace(input) {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing, closed = 0, true
+			} else if strings.ContainsRune(identifierQuotes, r) {
+				return "", "", fmt.Errorf("表名 [%s] 不能包含引号字符", input)
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '.':
+			if err := flush(); err != nil {
+				return "", "", err
+			}
+		case closed:
+			// 引号结束后只能是 . 或名称结尾，"a""b" 这类转义写法同样拒绝
+			if strings.ContainsRune(identifierQuotes, r) {
+				return "", "", fmt.Errorf("表名 [%s] 不能包含引号字符", input)
+			}
+			if !unicode.IsSpace(r) {
+				return "", "", fmt.Errorf("表名 [%s] 格式不正确", input)
+			}
+		case r == '"' || r == '`' || r == '[':
+			// 引号只能包围整个部分
+			if strings.TrimSpace(cur.String()) != "" {
+				return "", "", fmt.Errorf("表名 [%s] 不能包含引号字符", input)
+			}
+			closing, quoted = r, true
+			if r == '[' {
+				closing = ']'
+			}
+		case r == ']':
+			return "", "", fmt.Errorf("表名 [%s] 中的引号不匹配", input)
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if closing != 0 {
+		return "", "", fmt.Errorf("表名 [%s] 中的引号不匹配", input)
+	}
+	if err := flush(); err != nil {
+		return "", "", err
+	}
+
+	for _, p := range parts {
+		if p == "" {
+			return "", "", fmt.Errorf("表名 [%s] 格式不正确", input)
+		}
+	}
+	switch len(parts) {
+	case 1:
+		return "", parts[0], nil
+	case 2:
+		return parts[0], parts[1], nil
+	}
+	return "", "", fmt.Errorf("表名 [%s] 格式不正确，应为 表名 或 schema.表名", input)
+}
+
+// dialect 封装一种数据库在连接、读取表结构、生成语句、类型转换与批量写入上的差异。
+// 新增数据库只需实现该接口并在 dialects 中登记，导入、校验与建表流程不需要修改
+type dialect interface {
+	// name 返回用于日志与提示的数据库名称
+	name() string
+	// open 建立连接并确认数据库可用
+	open(ctx context.Context, p connParams) (*sql.DB, error)
+	// describe 返回连接测试成功时显示的连接描述
+	describe(p connParams) string
+	// quote 为列名等标识符加上引号
+	quote(ident string) string
+
+	// resolveTable 将用户输入的表名解析为实际存在的表，未指定 schema 时使用当前 schema
+	resolveTable(ctx context.Context, db *sql.DB, name string) (tableRef, error)
+
+	// 以下查询的参数均为 tableRef.args()，即 (schema, 表名)
+
+	// columnNamesQuery 按列顺序查询表的列名
+	columnNamesQuery() string
+	// columnsQuery 与 scanColumn 查询并解析表的完整列定义，主键与唯一标记由 keyColumnsQuery 补充。
+	// 查询语句可能因数据库版本不同而不同，db 用于查询版本
+	columnsQuery(ctx context.Context, db *sql.DB) (string, error)
+	scanColumn(rows *sql.Rows) (TableColumnInfo, error)
+	// keyColumnsQuery 查询属于主键或唯一约束的列，结果为 (列名, 'P' 或 'U')
+	keyColumnsQuery() string
+	// primaryKeyQuery 按键内顺序查询主键列
+	primaryKeyQuery() string
+	// uniqueKeysQuery 查询所有主键/唯一索引的 (索引名, 列名)，
+	// 为空表示 upsert 不要求键列上存在唯一索引
+	uniqueKeysQuery() string
+
+	// schemasQuery 查询当前用户可访问的 schema，currentSchemaQuery 查询会话的当前 schema
+	schemasQuery() string
+	currentSchemaQuery() string
+	// tablesQuery 按 schema 参数查询其中的表、视图与同义词，
+	// 结果为 (名称, TABLE/VIEW/SYNONYM, 估算行数, 注释, 同义词指向的表)，未知的值为 NULL
+	tablesQuery() string
+
+	// placeholder 生成第 n 个绑定参数的占位符，可在其中完成日期转换与截断
+	placeholder(c boundColumn, n int, enableTruncation bool) string
+	// convert 将文件中的文本转换为写入数据库的值
+	convert(c boundColumn, val string, enableTruncation bool) (interface{}, error)
+	// upsertSQL 生成单行 upsert 语句，以及多行 INSERT 需要追加的子句(不需要时为空)
+	upsertSQL(table, insertSQL string, names, placeholders []string, keyIdx []int) (string, string)
+	// upsertAnyUniqueKey 判断 upsert 是否在任一唯一索引冲突时都会更新已有的行，而不只是所选的键
+	upsertAnyUniqueKey() bool
+	// rowValueIn 判断是否支持 (k1,k2) IN ((..),(..)) 形式的多列比较
+	rowValueIn() bool
+	// writeBatch 在事务中写入一批数据，使用该数据库最快的批量方式
+	writeBatch(ctx context.Context, tx *sql.Tx, b *batch) error
+	// rowSavepoints 判断语句出错后是否必须回滚到保存点事务才能继续
+	rowSavepoints() bool
+	savepointSQL(name string) string
+	rollbackToSQL(name string) string
+	// errorColumn 从写入失败的错误中取出出错的列名，无法确定时返回空
+	errorColumn(err error) string
+
+	// truncateSQL、stagingSQL 与 dropTableSQL 生成清空表、按目标表结构建临时表与删除临时表的语句
+	truncateSQL(table string) string
+	stagingSQL(staging, columnList, table string) string
+	dropTableSQL(table string) string
+
+	// emptyStringIsNull 判断字符列写入空串时是否按 NULL 处理
+	emptyStringIsNull() bool
+	// integerRange 返回整数类型的取值范围，只受精度限制时 ok 为 false
+	integerRange(c TableColumnInfo) (min, max *big.Int, ok bool)
+	// integerFraction 返回带小数的值写入整数列时的问题级别与说明，为空时按精度舍入处理
+	integerFraction() (string, string)
+
+	// inferType 按样本统计结果给出建表时的列类型
+	inferType(s *columnStats) string
+	// foldIdentifier 按数据库对未加引号标识符的大小写处理转换
+	foldIdentifier(id string) string
+	// isReserved 判断标识符是否为保留字(不区分大小写)，建表时需要避开
+	isReserved(id string) bool
+	// identifierLimit 返回标识符的长度上限以及是否按字节计算
+	identifierLimit() (int, bool)
+	// createTableSuffix 追加在 CREATE TABLE 语句末尾的表选项
+	createTableSuffix() string
+}
+
+// 已支持的数据库，键为前端传入的 dbType
+var dialects = map[string]dialect{
+	"oracle":    oracleDialect{},
+	"mysql":     mysqlDialect{},
+	"postgres":  postgresDialect{},
+	"sqlserver": mssqlDialect{},
+	"sqlite":    sqliteDialect{},
+}
+
+// lookupDialect 按 dbType 查找对应的数据库实现
+func lookupDialect(dbType string) (dialect, error) {
+	d, ok := dialects[strings.ToLower(strings.TrimSpace(dbType))]
+	if !ok {
+		return nil, fmt.Errorf("不支持的数据库类型: %s", dbType)
+	}
+	return d, nil
+}
+
+// baseDialect 提供按标准 SQL 的默认实现，各数据库嵌入后只需覆盖不同的部分
+type baseDialect struct{}
+
+func (baseDialect) quote(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+func (baseDialect) uniqueKeysQuery() string { return "" }
+
+func (baseDialect) placeholder(c boundColumn, n int, enableTruncation bool) string { return "?" }
+
+func (baseDialect) convert(c boundColumn, val string, enableTruncation bool) (interface{}, error) {
+	return convertValue(c, val, false)
+}
+
+func (baseDialect) upsertAnyUniqueKey() bool { return false }
+func (baseDialect) rowValueIn() bool         { return true }
+func (baseDialect) rowSavepoints() bool      { return false }
+
+func (baseDialect) errorColumn(error) string { return "" }
+
+func (baseDialect) savepointSQL(name string) string  { return "SAVEPOINT " + name }
+func (baseDialect) rollbackToSQL(name string) string { return "ROLLBACK TO SAVEPOINT " + name }
+
+func (baseDialect) truncateSQL(table string) string { return "TRUNCATE TABLE " + table }
+
+func (baseDialect) stagingSQL(staging, columnList, table string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS SELECT %s FROM %s WHERE 1 = 0", staging, columnList, table)
+}
+
+func (baseDialect) dropTableSQL(table string) string { return "DROP TABLE " + table }
+
+func (baseDialect) emptyStringIsNull() bool { return false }
+
+func (baseDialect) integerRange(c TableColumnInfo) (*big.Int, *big.Int, bool) { return nil, nil, false }
+
+func (baseDialect) integerFraction() (string, string) { return "", "" }
+
+func (baseDialect) foldIdentifier(id string) string { return id }
+
+func (baseDialect) isReserved(id string) bool { return reservedWords[strings.ToUpper(id)] }
+
+func (baseDialect) createTableSuffix() string { return "" }
+
+// openDB 打开连接并 Ping 确认可用，错误信息中的连接串会隐藏密码
+func openDB(ctx context.Context, driver, dsn string) (*sql.DB, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, errors.New(redactSecrets(err.Error()))
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, errors.New(redactSecrets(err.Error()))
+	}
+	return db, nil
+}
+
+// batch 一批待写入的数据以及写入所需的语句
+type batch struct {
+	table        string          // 实际写入的表，安全替换模式下为临时表
+	columns      []string        // 未加引号的列名，供 COPY 等按名称写入的接口使用
+	columnList   string          // 加引号后以逗号分隔的列清单
+	placeholders []string        // 单行语句中每一列的占位符
+	writeSQL     string          // 单行 INSERT 或 upsert 语句
+	upsertSuffix string          // 多行 INSERT 后追加的 upsert 子句
+	upsert       bool            // 是否为 upsert 模式
+	cols         []boundColumn   // 写入的列
+	buffers      [][]interface{} // 按列存放的数据
+}
+
+// count 返回本批的行数
+func (b *batch) count() int {
+	return len(b.buffers[0])
+}
+
+// row 返回第 k 行的参数
+func (b *batch) row(k int) []interface{} {
+	args := make([]interface{}, len(b.buffers))
+	for i := range b.buffers {
+		args[i] = b.buffers[i][k]
+	}
+	return args
+}
+
+// execRows 使用预编译的单行语句逐行写入
+func execRows(ctx context.Context, tx *sql.Tx, b *batch) error {
+	stmt, err := tx.PrepareContext(ctx, b.writeSQL)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %v", err)
+	}
+	defer stmt.Close()
+	for k := 0; k < b.count(); k++ {
+		if _, err := stmt.ExecContext(ctx, b.row(k)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
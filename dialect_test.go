@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTableName(t *testing.T) {
+	tests := []struct {
+		dbType   string
+		input    string
+		schema   string
+		table    string
+		errorHas string
+	}{
+		{dbType: "oracle", input: "emp", table: "EMP"},
+		{dbType: "oracle", input: " scott.emp ", schema: "SCOTT", table: "EMP"},
+		{dbType: "oracle", input: `scott."Mixed Case"`, schema: "SCOTT", table: "Mixed Case"},
+		{dbType: "oracle", input: "SYS$T#1", table: "SYS$T#1"},
+		{dbType: "postgres", input: "Public.Orders", schema: "public", table: "orders"},
+		{dbType: "postgres", input: `"Public"."Orders"`, schema: "Public", table: "Orders"},
+		{dbType: "mysql", input: "`my db`.`order`", schema: "my db", table: "order"},
+		{dbType: "mysql", input: "Shop.Orders", schema: "Shop", table: "Orders"},
+		{dbType: "sqlserver", input: "[dbo].[Order Details]", schema: "dbo", table: "Order Details"},
+		{dbType: "sqlite", input: `main . "items"`, schema: "main", table: "items"},
+		{dbType: "oracle", input: "emp; DROP TABLE x", errorHas: "非法字符"},
+		{dbType: "oracle", input: "my table", errorHas: "非法字符"},
+		{dbType: "postgres", input: `"a""b"`, errorHas: "引号"},
+		{dbType: "postgres", input: `"abc`, errorHas: "引号不匹配"},
+		{dbType: "sqlserver", input: "dbo]", errorHas: "引号不匹配"},
+		{dbType: "sqlserver", input: "[a`b]", errorHas: "引号"},
+		{dbType: "mysql", input: "a`b`", errorHas: "引号"},
+		{dbType: "mysql", input: "a.b.c", errorHas: "应为 表名 或 schema.表名"},
+		{dbType: "mysql", input: "a.", errorHas: "格式不正确"},
+		{dbType: "mysql", input: "", errorHas: "格式不正确"},
+	}
+	for _, tt := range tests {
+		d, err := lookupDialect(tt.dbType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, table, err := splitTableName(d, tt.input)
+		if tt.errorHas != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+				t.Errorf("%s %q: 错误 = %v，应包含 %q", tt.dbType, tt.input, err, tt.errorHas)
+			}
+			continue
+		}
+		if err != nil || schema != tt.schema || table != tt.table {
+			t.Errorf("%s %q = (%q, %q, %v)，应为 (%q, %q)", tt.dbType, tt.input, schema, table, err, tt.schema, tt.table)
+		}
+	}
+}
@@ -2,22 +2,62 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function CancelImport():Promise<boolean>;
+
 export function CompareFields(arg1:Array<string>,arg2:Array<string>):Promise<Record<string, any>>;
 
-export function GetExcelHeaders(arg1:string):Promise<Array<string>>;
+export function DeleteProfile(arg1:string):Promise<void>;
+
+export function DuplicateProfile(arg1:string,arg2:string):Promise<void>;
+
+export function ExecuteDDL(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string):Promise<string>;
+
+export function GetColumnMetadata(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string):Promise<Array<main.TableColumnInfo>>;
+
+export function GetExcelHeaders(arg1:string,arg2:main.ImportOptions):Promise<Array<string>>;
 
 export function GetTableColumns(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string):Promise<Array<string>>;
 
 export function Greet(arg1:string):Promise<string>;
 
-export function ImportExcel(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:string,arg11:string):Promise<string>;
+export function ImportExcel(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:string,arg11:string,arg12:main.ImportOptions):Promise<string>;
+
+export function InferTableDDL(arg1:string,arg2:string,arg3:string,arg4:main.ImportOptions):Promise<main.TableDDL>;
+
+export function ListProfiles():Promise<Array<main.ProfileInfo>>;
+
+export function ListSchemas(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string):Promise<Array<string>>;
+
+export function ListSheets(arg1:string,arg2:main.ImportOptions):Promise<Array<main.SheetInfo>>;
+
+export function ListTables(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:string):Promise<main.TableList>;
 
 export function LoadConfig():Promise<main.DBConfig>;
 
+export function LoadProfile(arg1:string):Promise<main.DBConfig>;
+
+export function OpenJob():Promise<main.JobSetup>;
+
+export function RenameProfile(arg1:string,arg2:string):Promise<void>;
+
 export function SaveConfig(arg1:main.DBConfig):Promise<string>;
 
+export function SaveJob(arg1:string,arg2:string,arg3:string,arg4:string,arg5:main.ImportOptions):Promise<string>;
+
+export function SaveProfile(arg1:string,arg2:main.DBConfig,arg3:boolean):Promise<void>;
+
+export function SaveRejectFile(arg1:string):Promise<string>;
+
 export function SelectExcelFile():Promise<string>;
 
+export function SetDefaultProfile(arg1:string):Promise<void>;
+
+export function SetMasterPassphrase(arg1:string):Promise<string>;
+
 export function TestDatabaseConnection(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string):Promise<string>;
 
+export function UnlockProfile(arg1:string,arg2:string):Promise<main.DBConfig>;
+
 export function UpdateProgress(arg1:number,arg2:string):Promise<void>;
+
+export function ValidateImport(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string,arg6:string,arg7:string,arg8:string,arg9:string,arg10:string,arg11:string,arg12:main.ImportOptions):Promise<main.ValidationReport>;
@@ -2,12 +2,32 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function CancelImport() {
+  return window['go']['main']['App']['CancelImport']();
+}
+
 export function CompareFields(arg1, arg2) {
   return window['go']['main']['App']['CompareFields'](arg1, arg2);
 }
 
-export function GetExcelHeaders(arg1) {
-  return window['go']['main']['App']['GetExcelHeaders'](arg1);
+export function DeleteProfile(arg1) {
+  return window['go']['main']['App']['DeleteProfile'](arg1);
+}
+
+export function DuplicateProfile(arg1, arg2) {
+  return window['go']['main']['App']['DuplicateProfile'](arg1, arg2);
+}
+
+export function ExecuteDDL(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['ExecuteDDL'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
+export function GetColumnMetadata(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
+  return window['go']['main']['App']['GetColumnMetadata'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9);
+}
+
+export function GetExcelHeaders(arg1, arg2) {
+  return window['go']['main']['App']['GetExcelHeaders'](arg1, arg2);
 }
 
 export function GetTableColumns(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9) {
@@ -18,26 +38,86 @@ export function Greet(arg1) {
   return window['go']['main']['App']['Greet'](arg1);
 }
 
-export function ImportExcel(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11) {
-  return window['go']['main']['App']['ImportExcel'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11);
+export function ImportExcel(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12) {
+  return window['go']['main']['App']['ImportExcel'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12);
+}
+
+export function InferTableDDL(arg1, arg2, arg3, arg4) {
+  return window['go']['main']['App']['InferTableDDL'](arg1, arg2, arg3, arg4);
+}
+
+export function ListProfiles() {
+  return window['go']['main']['App']['ListProfiles']();
+}
+
+export function ListSchemas(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
+  return window['go']['main']['App']['ListSchemas'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
+}
+
+export function ListSheets(arg1, arg2) {
+  return window['go']['main']['App']['ListSheets'](arg1, arg2);
+}
+
+export function ListTables(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10) {
+  return window['go']['main']['App']['ListTables'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10);
 }
 
 export function LoadConfig() {
   return window['go']['main']['App']['LoadConfig']();
 }
 
+export function LoadProfile(arg1) {
+  return window['go']['main']['App']['LoadProfile'](arg1);
+}
+
+export function OpenJob() {
+  return window['go']['main']['App']['OpenJob']();
+}
+
+export function RenameProfile(arg1, arg2) {
+  return window['go']['main']['App']['RenameProfile'](arg1, arg2);
+}
+
 export function SaveConfig(arg1) {
   return window['go']['main']['App']['SaveConfig'](arg1);
 }
 
+export function SaveJob(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['SaveJob'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function SaveProfile(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SaveProfile'](arg1, arg2, arg3);
+}
+
+export function SaveRejectFile(arg1) {
+  return window['go']['main']['App']['SaveRejectFile'](arg1);
+}
+
 export function SelectExcelFile() {
   return window['go']['main']['App']['SelectExcelFile']();
 }
 
+export function SetDefaultProfile(arg1) {
+  return window['go']['main']['App']['SetDefaultProfile'](arg1);
+}
+
+export function SetMasterPassphrase(arg1) {
+  return window['go']['main']['App']['SetMasterPassphrase'](arg1);
+}
+
 export function TestDatabaseConnection(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8) {
   return window['go']['main']['App']['TestDatabaseConnection'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8);
 }
 
+export function UnlockProfile(arg1, arg2) {
+  return window['go']['main']['App']['UnlockProfile'](arg1, arg2);
+}
+
 export function UpdateProgress(arg1, arg2) {
   return window['go']['main']['App']['UpdateProgress'](arg1, arg2);
 }
+
+export function ValidateImport(arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12) {
+  return window['go']['main']['App']['ValidateImport'](arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8, arg9, arg10, arg11, arg12);
+}
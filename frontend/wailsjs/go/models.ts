@@ -1,5 +1,25 @@
 export namespace main {
 	
+	export class ColumnMapping {
+	    target: string;
+	    action: string;
+	    source: string;
+	    constant: string;
+	    transforms?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ColumnMapping(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.action = source["action"];
+	        this.source = source["source"];
+	        this.constant = source["constant"];
+	        this.transforms = source["transforms"];
+	    }
+	}
 	export class DBConfig {
 	    dbType: string;
 	    host: string;
@@ -12,6 +32,10 @@ export namespace main {
 	    serviceName: string;
 	    tnsConnection: string;
 	    truncateChars: string;
+	    savePassword: boolean;
+	    usePassphrase: boolean;
+	    passwordLocked?: boolean;
+	    importDefaults: ImportOptions;
 	
 	    static createFrom(source: any = {}) {
 	        return new DBConfig(source);
@@ -30,7 +54,409 @@ export namespace main {
 	        this.serviceName = source["serviceName"];
 	        this.tnsConnection = source["tnsConnection"];
 	        this.truncateChars = source["truncateChars"];
+	        this.savePassword = source["savePassword"];
+	        this.usePassphrase = source["usePassphrase"];
+	        this.passwordLocked = source["passwordLocked"];
+	        this.importDefaults = this.convertValues(source["importDefaults"], ImportOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SheetTable {
+	    sheet: string;
+	    table: string;
+	    mappings: ColumnMapping[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SheetTable(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheet = source["sheet"];
+	        this.table = source["table"];
+	        this.mappings = this.convertValues(source["mappings"], ColumnMapping);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class InferredColumn {
+	    header: string;
+	    name: string;
+	    type: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new InferredColumn(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.header = source["header"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	    }
+	}
+	export class ImportOptions {
+	    delimiter: string;
+	    sheetName: string;
+	    sheetTables: SheetTable[];
+	    mappings: ColumnMapping[];
+	    loadMode: string;
+	    keyColumns: string[];
+	    deleteWhere: string;
+	    atomic: boolean;
+	    maxErrors: number;
+	    headerRow: number;
+	    dataStartRow: number;
+	    footerRows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportOptions(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.delimiter = source["delimiter"];
+	        this.sheetName = source["sheetName"];
+	        this.sheetTables = this.convertValues(source["sheetTables"], SheetTable);
+	        this.mappings = this.convertValues(source["mappings"], ColumnMapping);
+	        this.loadMode = source["loadMode"];
+	        this.keyColumns = source["keyColumns"];
+	        this.deleteWhere = source["deleteWhere"];
+	        this.atomic = source["atomic"];
+	        this.maxErrors = source["maxErrors"];
+	        this.headerRow = source["headerRow"];
+	        this.dataStartRow = source["dataStartRow"];
+	        this.footerRows = source["footerRows"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class JobSetup {
+	    path: string;
+	    name: string;
+	    profile: string;
+	    tableName: string;
+	    filePath: string;
+	    fileError: string;
+	    truncateChars: string;
+	    options: ImportOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobSetup(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.name = source["name"];
+	        this.profile = source["profile"];
+	        this.tableName = source["tableName"];
+	        this.filePath = source["filePath"];
+	        this.fileError = source["fileError"];
+	        this.truncateChars = source["truncateChars"];
+	        this.options = this.convertValues(source["options"], ImportOptions);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProfileInfo {
+	    name: string;
+	    dbType: string;
+	    description: string;
+	    default: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProfileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.dbType = source["dbType"];
+	        this.description = source["description"];
+	        this.default = source["default"];
+	    }
+	}
+	export class SheetInfo {
+	    name: string;
+	    rowCount: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SheetInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.rowCount = source["rowCount"];
+	    }
+	}
+	export class TableDDL {
+	    table: string;
+	    columns: InferredColumn[];
+	    ddl: string;
+	    sampleRows: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableDDL(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.table = source["table"];
+	        this.columns = this.convertValues(source["columns"], InferredColumn);
+	        this.ddl = source["ddl"];
+	        this.sampleRows = source["sampleRows"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TableColumnInfo {
+	    columnName: string;
+	    dataType: string;
+	    columnType: string;
+	    dataLength: number;
+	    nullable: boolean;
+	    precision: number;
+	    scale: number;
+	    charLength: number;
+	    octetLength: number;
+	    charUsed: string;
+	    default: string;
+	    hasDefault: boolean;
+	    identity: boolean;
+	    virtual: boolean;
+	    primaryKey: boolean;
+	    unique: boolean;
+	    comment: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableColumnInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.columnName = source["columnName"];
+	        this.dataType = source["dataType"];
+	        this.columnType = source["columnType"];
+	        this.dataLength = source["dataLength"];
+	        this.nullable = source["nullable"];
+	        this.precision = source["precision"];
+	        this.scale = source["scale"];
+	        this.charLength = source["charLength"];
+	        this.octetLength = source["octetLength"];
+	        this.charUsed = source["charUsed"];
+	        this.default = source["default"];
+	        this.hasDefault = source["hasDefault"];
+	        this.identity = source["identity"];
+	        this.virtual = source["virtual"];
+	        this.primaryKey = source["primaryKey"];
+	        this.unique = source["unique"];
+	        this.comment = source["comment"];
+	    }
+	}
+	export class TableInfo {
+	    schema: string;
+	    name: string;
+	    type: string;
+	    rows: number;
+	    comment: string;
+	    target: string;
+	    fullName: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schema = source["schema"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.rows = source["rows"];
+	        this.comment = source["comment"];
+	        this.target = source["target"];
+	        this.fullName = source["fullName"];
+	    }
+	}
+	export class TableList {
+	    schema: string;
+	    tables: TableInfo[];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TableList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schema = source["schema"];
+	        this.tables = this.convertValues(source["tables"], TableInfo);
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ValidationIssue {
+	    sheet: string;
+	    table: string;
+	    row: number;
+	    column: string;
+	    value: string;
+	    level: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sheet = source["sheet"];
+	        this.table = source["table"];
+	        this.row = source["row"];
+	        this.column = source["column"];
+	        this.value = source["value"];
+	        this.level = source["level"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ValidationReport {
+	    totalRows: number;
+	    errorRows: number;
+	    errorCount: number;
+	    warningCount: number;
+	    issues: ValidationIssue[];
+	    truncated: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ValidationReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.totalRows = source["totalRows"];
+	        this.errorRows = source["errorRows"];
+	        this.errorCount = source["errorCount"];
+	        this.warningCount = source["warningCount"];
+	        this.issues = this.convertValues(source["issues"], ValidationIssue);
+	        this.truncated = source["truncated"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -0,0 +1,581 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// SheetTable 多工作表导入时，工作表与目标表的对应关系
+type SheetTable struct {
+	Sheet    string          `json:"sheet"`
+	Table    string          `json:"table"`
+	Mappings []ColumnMapping `json:"mappings"` // 该工作表的列映射，留空时按列名自动匹配
+}
+
+// importResult 单个工作表的导入统计
+type importResult struct {
+	TotalRows  int    `json:"totalRows"`
+	Imported   int    `json:"imported"`
+	Inserted   int    `json:"inserted"`
+	Updated    int    `json:"updated"`
+	Unchanged  int    `json:"unchanged,omitempty"`  // 只映射了键列时，已存在而未做任何修改的行数
+	Rejected   int    `json:"rejected"`             // 出错后跳过并写入错误记录的行数
+	RejectFile string `json:"rejectFile,omitempty"` // 错误记录文件路径
+	upsert     bool
+	keysOnly   bool // upsert 只写入键列，已存在的行没有可更新的列
+}
+
+// sheetImport 一个工作表导入到一张表的结果
+type sheetImport struct {
+	Sheet string `json:"sheet,omitempty"`
+	Table string `json:"table"`
+	importResult
+	Error string `json:"error,omitempty"`
+}
+
+func (r importResult) summary() string {
+	s := fmt.Sprintf("excel行数:%d,成功导入:%d", r.TotalRows, r.Imported)
+	if r.upsert {
+		if r.keysOnly {
+			s += fmt.Sprintf("(新增:%d,已存在未修改:%d)", r.Inserted, r.Unchanged)
+		} else {
+			s += fmt.Sprintf("(新增:%d,更新:%d)", r.Inserted, r.Updated)
+		}
+	}
+	if r.Rejected > 0 {
+		s += fmt.Sprintf(",出错跳过:%d\n错误记录文件: %s", r.Rejected, r.RejectFile)
+	}
+	return s
+}
+
+// sqlExecer 由 *sql.DB 与 *sql.Tx 共同实现，便于同一段逻辑在事务内外执行
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// canceledResult 返回导入被取消后实际保留的结果：单事务与安全替换模式下已写入的数据全部回滚，
+// 其余模式保留取消前已提交的批次
+func canceledResult(res importResult, atomic bool, opts ImportOptions) importResult {
+	if mode, _ := normalizeLoadMode(opts.LoadMode); atomic || mode == loadReplace {
+		res.Imported, res.Inserted, res.Updated, res.Unchanged = 0, 0, 0, 0
+	}
+	return res
+}
+
+// canceledMessage 导入被取消时的提示，说明取消前已提交的行数
+func canceledMessage(committed int) string {
+	if committed == 0 {
+		return "导入已取消，已回滚，未写入任何数据"
+	}
+	return fmt.Sprintf("导入已取消，取消前已提交 %d 行，其余数据未导入", committed)
+}
+
+// importFile 将文件导入到 tableName，配置了多工作表映射时按映射分别导入。
+// 返回各工作表的结果与汇总信息；导入失败(包括多工作表中有工作表失败)时返回的错误即为完整的提示信息。
+// ctx 取消后停止读取与写入，回滚未提交的事务，结果中只统计已提交的行
+func (a *App) importFile(ctx context.Context, db *sql.DB, d dialect, tableName, filePath string, enableTruncation bool, opts ImportOptions) ([]sheetImport, string, error) {
+	// 单事务模式下整个文件在一个事务中写入，全部成功才提交
+	var tx *sql.Tx
+	var err error
+	if opts.Atomic {
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			return nil, "", fmt.Errorf("错误: 开启事务失败: %v", err)
+		}
+		defer tx.Rollback()
+	}
+
+	// 未配置多工作表映射时，将选定的工作表导入到 tableName
+	if len(opts.SheetTables) == 0 {
+		res, err := a.importSheet(ctx, db, tx, d, tableName, filePath, enableTruncation, opts)
+		if err == nil && tx != nil {
+			if err = tx.Commit(); err != nil {
+				err = fmt.Errorf("提交事务失败: %v", err)
+			}
+		}
+		sheets := []sheetImport{{Sheet: opts.SheetName, Table: tableName, importResult: res}}
+		if err != nil && ctx.Err() != nil {
+			sheets[0].importResult = canceledResult(res, tx != nil, opts)
+			sheets[0].Error = "已取消"
+			a.UpdateProgress(100, "导入已取消")
+			return sheets, "", errors.New(canceledMessage(sheets[0].Imported))
+		}
+		if err != nil {
+			sheets[0].Error = err.Error()
+			if tx != nil {
+				sheets[0].Imported = 0
+				return sheets, "", fmt.Errorf("%v\n导入失败，已全部回滚，未写入任何数据", err)
+			}
+			return sheets, "", err
+		}
+		// 导入完成
+		a.UpdateProgress(100, fmt.Sprintf("导入完成: %d/%d 行", res.Imported, res.TotalRows))
+		return sheets, res.summary(), nil
+	}
+
+	// 多个工作表分别导入到各自的表，每个工作表独立提交，汇总各自的结果；
+	// 单事务模式下任一工作表失败即停止并回滚全部工作表
+	var sheets []sheetImport
+	var lines []string
+	var totalRows, imported int
+	failed := false
+	for _, st := range opts.SheetTables {
+		sheetOpts := opts
+		sheetOpts.SheetName = st.Sheet
+		sheetOpts.SheetTables = nil
+		sheetOpts.Mappings = st.Mappings
+
+		res, err := a.importSheet(ctx, db, tx, d, st.Table, filePath, enableTruncation, sheetOpts)
+		if err != nil && ctx.Err() != nil {
+			// 取消后不再导入后续工作表；单事务模式下之前的工作表也一并回滚
+			res = canceledResult(res, tx != nil, sheetOpts)
+			sheets = append(sheets, sheetImport{Sheet: st.Sheet, Table: st.Table, importResult: res, Error: "已取消"})
+			lines = append(lines, fmt.Sprintf("工作表[%s] -> 表[%s]: 已取消 (已提交%d行)", st.Sheet, st.Table, res.Imported))
+			committed := imported + res.Imported
+			if tx != nil {
+				for i := range sheets {
+					sheets[i].Imported, sheets[i].Inserted, sheets[i].Updated, sheets[i].Unchanged = 0, 0, 0, 0
+				}
+				committed = 0
+			}
+			lines = append(lines, canceledMessage(committed))
+			a.UpdateProgress(100, "导入已取消")
+			return sheets, "", errors.New(strings.Join(lines, "\n"))
+		}
+		sheets = append(sheets, sheetImport{Sheet: st.Sheet, Table: st.Table, importResult: res})
+		totalRows += res.TotalRows
+		imported += res.Imported
+		if err != nil {
+			sheets[len(sheets)-1].Error = err.Error()
+			if tx != nil {
+				lines = append(lines, fmt.Sprintf("工作表[%s] -> 表[%s]: 失败: %v", st.Sheet, st.Table, err))
+				lines = append(lines, "导入失败，已全部回滚，未写入任何数据")
+				return sheets, "", errors.New(strings.Join(lines, "\n"))
+			}
+			lines = append(lines, fmt.Sprintf("工作表[%s] -> 表[%s]: 失败: %v (已导入%d行)", st.Sheet, st.Table, err, res.Imported))
+			failed = true
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("工作表[%s] -> 表[%s]: %s", st.Sheet, st.Table, res.summary()))
+	}
+
+	if tx != nil {
+		if err := tx.Commit(); err != nil {
+			lines = append(lines, fmt.Sprintf("提交事务失败: %v", err), "导入失败，已全部回滚，未写入任何数据")
+			return sheets, "", errors.New(strings.Join(lines, "\n"))
+		}
+	}
+
+	a.UpdateProgress(100, fmt.Sprintf("导入完成: %d/%d 行", imported, totalRows))
+	if failed {
+		return sheets, "", errors.New(strings.Join(lines, "\n"))
+	}
+	return sheets, strings.Join(lines, "\n"), nil
+}
+
+// importSheet 将文件中的一个工作表导入到 tableName，出错时返回已导入的行数。
+// tx 不为空时所有写入都在该事务中进行，由调用方统一提交或回滚；
+// 否则每一批单独提交。
+func (a *App) importSheet(ctx context.Context, db *sql.DB, tx *sql.Tx, d dialect, tableName, filePath string, enableTruncation bool, opts ImportOptions) (importResult, error) {
+	var result importResult
+	var err error
+
+	// 根据数据库类型设置不同的批量大小
+	batchSize := 1000
+
+	// Excel 与 CSV 统一使用流式读取，避免一次性加载整个文件
+	// 按配置定位标题行与数据起始行
+	reader, err := openTableReader(filePath, opts)
+	if err != nil {
+		return result, err
+	}
+	defer reader.Close()
+
+	excelHeaders := reader.Headers()
+
+	// 查询表结构
+	// 解析表名(支持 schema.表名 与同义词)，生成的语句只使用解析后加引号的名称
+	ref, err := resolveTable(ctx, db, d, tableName)
+	if err != nil {
+		return result, err
+	}
+	table := qualifiedName(d, ref)
+	dbCols, err := queryTableColumns(ctx, db, d, ref)
+	if err != nil {
+		return result, err
+	}
+
+	// 按列映射配置绑定表的每一列
+	boundCols, err := bindColumns(dbCols, excelHeaders, opts.Mappings)
+	if err != nil {
+		return result, err
+	}
+
+	// 准备 SQL 模板 - 占位符由各数据库生成，可在其中完成日期转换与截断
+	// 只插入映射到文件列或固定值的列，跳过和交给默认值的列不出现在列清单中
+	var placeholders []string
+	var insertCols []boundColumn
+	var columnNames, quotedNames []string
+	for _, c := range boundCols {
+		if c.Action == mapSkip || c.Action == mapDefault {
+			continue
+		}
+		insertCols = append(insertCols, c)
+		columnNames = append(columnNames, c.ColumnName)
+		quotedNames = append(quotedNames, d.quote(c.ColumnName))
+		placeholders = append(placeholders, d.placeholder(c, len(insertCols), enableTruncation))
+	}
+	if len(insertCols) == 0 {
+		return result, fmt.Errorf("列映射配置错误: 没有任何列需要从文件导入")
+	}
+	columnList := strings.Join(quotedNames, ",")
+
+	loadMode, err := normalizeLoadMode(opts.LoadMode)
+	if err != nil {
+		return result, err
+	}
+	if err := checkMaxErrors(opts.MaxErrors); err != nil {
+		return result, err
+	}
+
+	// 建临时表是 DDL，会隐式提交当前事务，因此不能与单事务模式同时使用
+	if loadMode == loadReplace && tx != nil {
+		return result, fmt.Errorf("安全替换模式本身即为全部成功才替换，无需同时开启单事务导入")
+	}
+	// 跳过的出错行不会写入临时表，替换后这些行会从目标表中消失，因此安全替换要求每一行都导入成功
+	if loadMode == loadReplace && opts.MaxErrors != 0 {
+		return result, fmt.Errorf("安全替换模式要求所有行都导入成功，不能同时设置允许跳过的出错行数")
+	}
+
+	// 安全替换模式先导入到结构相同的临时表，全部成功后再整体替换目标表的数据
+	targetTable := table
+	if loadMode == loadReplace {
+		targetTable, err = createStagingTable(ctx, db, d, ref, columnList)
+		if err != nil {
+			return result, err
+		}
+		defer dropStagingTable(db, d, targetTable)
+	}
+	insertSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", targetTable, columnList, strings.Join(placeholders, ","))
+
+	// upsert 模式下由各数据库生成单行 upsert 语句(MERGE、ON CONFLICT 或 ON DUPLICATE KEY UPDATE)
+	writeSQL := insertSQL
+	var upsertSuffix string
+	var keyIdx []int
+	if loadMode == loadUpsert {
+		result.upsert = true
+		keyIdx, err = resolveKeyColumns(ctx, db, d, ref, opts.KeyColumns, insertCols, dbCols)
+		if err != nil {
+			return result, err
+		}
+		result.keysOnly = len(keyIdx) == len(insertCols)
+		writeSQL, upsertSuffix = d.upsertSQL(table, insertSQL, quotedNames, placeholders, keyIdx)
+	}
+
+	// 清空模式在映射校验通过后、写入数据前清理目标表
+	if loadMode == loadTruncate || loadMode == loadDelete {
+		clearMode, where := loadMode, opts.DeleteWhere
+		var ex sqlExecer = db
+		if tx != nil {
+			// TRUNCATE 是 DDL 会隐式提交，单事务模式下改为在事务内 DELETE 全表
+			if clearMode == loadTruncate {
+				clearMode, where = loadDelete, "1 = 1"
+			}
+			ex = tx
+		}
+		if err := clearTable(ctx, ex, d, table, clearMode, where); err != nil {
+			return result, err
+		}
+	}
+
+	columnBuffers := make([][]interface{}, len(insertCols))
+	for i := range columnBuffers {
+		columnBuffers[i] = make([]interface{}, 0, batchSize)
+	}
+	// 记录缓冲区中每一行在文件中的行号，用于错误定位
+	lineNumbers := make([]int, 0, batchSize)
+
+	// 允许出错继续时，出错的行连同原始数据写入错误记录文件，其余行照常导入
+	var rejects *rejectWriter
+	var rawRows [][]string
+	if opts.MaxErrors != 0 {
+		rejects = newRejectWriter(excelHeaders)
+		defer rejects.Close()
+	}
+	reject := func(line int, column, message string, row []string) error {
+		if err := rejects.add(line, column, message, row); err != nil {
+			return err
+		}
+		result.Rejected++
+		result.RejectFile = rejects.path
+		if opts.MaxErrors > 0 && result.Rejected > opts.MaxErrors {
+			return fmt.Errorf("出错行数超过上限 %d，导入已停止 (错误记录文件: %s)", opts.MaxErrors, rejects.path)
+		}
+		return nil
+	}
+
+	// 批量刷新与错误探测逻辑
+	flush := func() error {
+		count := len(columnBuffers[0])
+		if count == 0 {
+			return nil
+		}
+
+		// 单事务模式使用调用方的事务，否则每一批单独开启事务
+		batchTx := tx
+		if batchTx == nil {
+			batchTx, err = db.BeginTx(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("开启事务失败: %v", err)
+			}
+		}
+		// 写入失败时只回滚本批自己开启的事务，调用方的事务由调用方回滚
+		rollback := func() {
+			if tx == nil {
+				batchTx.Rollback()
+			}
+		}
+		commit := func(imported, inserted int) error {
+			if tx == nil {
+				if err := batchTx.Commit(); err != nil {
+					return err
+				}
+			}
+			result.Imported += imported
+			result.Inserted += inserted
+			if result.keysOnly {
+				result.Unchanged += imported - inserted
+			} else {
+				result.Updated += imported - inserted
+			}
+			return nil
+		}
+
+		// upsert 前先统计本批中哪些键是新的，用于分别汇总新增与更新的行数
+		inserted := count
+		if result.upsert {
+			n, err := countNewKeys(ctx, batchTx, d, table, insertCols, keyIdx, columnBuffers, enableTruncation)
+			if err != nil {
+				rollback()
+				return fmt.Errorf("统计已存在的键失败 (第%d行起): %v", lineNumbers[0], err)
+			}
+			inserted = n
+		}
+
+		// 批量语句失败时可能已写入了出错行之前的部分行(如 Oracle 数组绑定)，
+		// 先设置保存点，失败后撤销本批的写入再逐行重试
+		if _, err := batchTx.ExecContext(ctx, d.savepointSQL("csv2o_batch")); err != nil {
+			rollback()
+			return fmt.Errorf("设置保存点失败: %v", err)
+		}
+
+		// 批量写入失败后在同一事务中逐行重试以定位出错的行。
+		// 未允许出错继续时遇到第一个出错行即停止，本批整体回滚；
+		// 否则出错行写入错误记录，其余行随本批提交。
+		locate := func(batchErr error) error {
+			if _, err := batchTx.ExecContext(ctx, d.rollbackToSQL("csv2o_batch")); err != nil {
+				rollback()
+				return fmt.Errorf("批量插入失败: %v (回滚到保存点失败: %v)", batchErr, err)
+			}
+			imported, inserted, failed := 0, 0, 0
+			for k := 0; k < count; k++ {
+				singleArgs := make([]interface{}, len(insertCols))
+				rowBuffers := make([][]interface{}, len(insertCols))
+				for cIdx := range insertCols {
+					singleArgs[cIdx] = columnBuffers[cIdx][k]
+					rowBuffers[cIdx] = columnBuffers[cIdx][k : k+1]
+				}
+				// PostgreSQL 中语句出错会使整个事务失效，SQL Server 的部分错误也会中止整批语句，
+				// 每一行都需要单独的保存点
+				if d.rowSavepoints() {
+					if _, err := batchTx.ExecContext(ctx, d.savepointSQL("csv2o_row")); err != nil {
+						rollback()
+						return fmt.Errorf("设置保存点失败: %v", err)
+					}
+				}
+				newRows := 1
+				if result.upsert {
+					n, err := countNewKeys(ctx, batchTx, d, table, insertCols, keyIdx, rowBuffers, enableTruncation)
+					if err != nil {
+						rollback()
+						return fmt.Errorf("统计已存在的键失败 (第%d行): %v", lineNumbers[k], err)
+					}
+					newRows = n
+				}
+				if _, sErr := batchTx.ExecContext(ctx, writeSQL, singleArgs...); sErr != nil {
+					// 取消导致的失败不是数据错误，不写入错误记录
+					if ctx.Err() != nil {
+						rollback()
+						return ctx.Err()
+					}
+					eLine := lineNumbers[k]
+					log.Printf("单条插入失败 - 行%d: %v", eLine, sErr)
+					if rejects == nil {
+						rollback()
+						return fmt.Errorf("数据库插入失败 (第%d行): %v", eLine, sErr)
+					}
+					if d.rowSavepoints() {
+						if _, err := batchTx.ExecContext(ctx, d.rollbackToSQL("csv2o_row")); err != nil {
+							rollback()
+							return fmt.Errorf("回滚到保存点失败 (第%d行): %v", eLine, err)
+						}
+					}
+					failed++
+					column := failedColumn(d, sErr, insertCols, rawRows[k], enableTruncation)
+					if err := reject(eLine, column, sErr.Error(), rawRows[k]); err != nil {
+						rollback()
+						return err
+					}
+					continue
+				}
+				imported++
+				inserted += newRows
+			}
+			if failed == 0 && rejects == nil {
+				// 如果所有单条插入都成功，说明是批量插入的系统性问题，返回原始错误
+				rollback()
+				return fmt.Errorf("批量插入失败，但单条重试都成功，可能存在系统性问题: %v", batchErr)
+			}
+			return commit(imported, inserted)
+		}
+
+		// 按各数据库最快的方式写入整批，失败后逐行定位出错的行
+		b := &batch{
+			table:        targetTable,
+			columns:      columnNames,
+			columnList:   columnList,
+			placeholders: placeholders,
+			writeSQL:     writeSQL,
+			upsertSuffix: upsertSuffix,
+			upsert:       result.upsert,
+			cols:         insertCols,
+			buffers:      columnBuffers,
+		}
+		if err := d.writeBatch(ctx, batchTx, b); err != nil {
+			if ctx.Err() != nil {
+				rollback()
+				return ctx.Err()
+			}
+			log.Printf("%s 批量写入失败: %v", d.name(), err)
+			return locate(err)
+		}
+
+		return commit(count, inserted)
+	}
+
+	// 逐行读取并处理数据
+	lineNo := 0
+	values := make([]interface{}, len(insertCols))
+readLoop:
+	for reader.Next() {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		row := reader.Row()
+		lineNo = reader.Line()
+		result.TotalRows++
+		for j, dbCol := range insertCols {
+			v, cErr := d.convert(dbCol, dbCol.value(row), enableTruncation)
+			if cErr != nil {
+				if rejects == nil {
+					return result, fmt.Errorf("行 %d %v", lineNo, cErr)
+				}
+				if err := reject(lineNo, dbCol.ColumnName, cErr.Error(), row); err != nil {
+					return result, err
+				}
+				continue readLoop
+			}
+			values[j] = v
+		}
+
+		for j, v := range values {
+			columnBuffers[j] = append(columnBuffers[j], v)
+		}
+		lineNumbers = append(lineNumbers, lineNo)
+		if rejects != nil {
+			// 读取器会复用行缓冲区，需要保留一份原始行用于写入错误记录
+			rawRows = append(rawRows, append([]string(nil), row...))
+		}
+
+		if len(lineNumbers) >= batchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+			for j := range columnBuffers {
+				columnBuffers[j] = columnBuffers[j][:0]
+			}
+			lineNumbers = lineNumbers[:0]
+			rawRows = rawRows[:0]
+
+			// 更新进度（按已读取的字节数或行数估算）
+			a.reportReadProgress(reader, result.TotalRows, opts.SheetName)
+		}
+	}
+	if err := reader.Err(); err != nil {
+		return result, fmt.Errorf("%v (第%d行之后)", err, lineNo)
+	}
+
+	// 写入最后一批不足 batchSize 的数据
+	if err := flush(); err != nil {
+		return result, err
+	}
+
+	if loadMode == loadReplace {
+		a.UpdateProgress(99, fmt.Sprintf("正在用临时表替换 [%s] 的数据...", tableName))
+		if err := replaceFromStaging(ctx, db, table, targetTable, columnList, result.Imported); err != nil {
+			imported := result.Imported
+			result.Imported = 0
+			return result, fmt.Errorf("%v (已校验的 %d 行未写入目标表，目标表数据保持不变)", err, imported)
+		}
+	}
+
+	return result, nil
+}
+
+// convertValue 将文件中的文本转换为写入数据库的值：日期统一为 "2006-01-02 15:04:05"，
+// 日期、数值、布尔与 JSON 列的空串写入 NULL，其余值原样传递。
+// truncate 为真时按列的字符长度截断字符串，供无法在写入时调用函数截断的数据库使用
+func convertValue(c boundColumn, val string, truncate bool) (interface{}, error) {
+	switch c.kind() {
+	case kindString:
+		if truncate && c.CharLength > 0 {
+			if r := []rune(val); len(r) > c.CharLength {
+				return string(r[:c.CharLength]), nil
+			}
+		}
+	case kindBoolean:
+		if val == "" {
+			return nil, nil
+		}
+		b, err := parseBool(val)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	case kindJSON:
+		if val == "" {
+			return nil, nil
+		}
+		if err := checkJSON(val); err != nil {
+			return nil, fmt.Errorf("%v: %s", err, val)
+		}
+	case kindDate:
+		if val == "" {
+			return nil, nil
+		}
+		t, err := tryParseDate(val)
+		if err != nil {
+			return nil, fmt.Errorf("日期格式不规范: %s", val)
+		}
+		return t.Format("2006-01-02 15:04:05"), nil
+	case kindInteger, kindDecimal, kindFloat:
+		if val == "" {
+			return nil, nil
+		}
+	}
+	return val, nil
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试使用的目标表：name 有默认值，便于只映射部分列；qty 不允许为负数，用于制造写入失败的行
+const testTableDDL = `CREATE TABLE items (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL DEFAULT '',
+	qty  INTEGER CHECK (qty >= 0)
+)`
+
+// openTestDB 在临时目录中创建 SQLite 数据库，建好 items 表并写入初始数据
+func openTestDB(t *testing.T, seed ...string) *sql.DB {
+	t.Helper()
+	db, err := sqliteOpen(context.Background(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("打开数据库失败: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for _, stmt := range append([]string{testTableDDL}, seed...) {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("执行 %s 失败: %v", stmt, err)
+		}
+	}
+	return db
+}
+
+// writeTestCSV 写入以 id,name,qty 为标题行的 CSV 文件
+func writeTestCSV(t *testing.T, rows ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "items.csv")
+	content := "id,name,qty\n" + strings.Join(rows, "\n") + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// itemRows 按 id 顺序读出 items 表，每行写成 id:name:qty
+func itemRows(t *testing.T, db *sql.DB) []string {
+	t.Helper()
+	rows, err := db.Query(`SELECT id, name, COALESCE(qty, '') FROM items ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got []string
+	for rows.Next() {
+		var id int
+		var name, qty string
+		if err := rows.Scan(&id, &name, &qty); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%d:%s:%s", id, name, qty))
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func itemCount(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM items`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+// importCounts 导入结果中需要比较的统计
+type importCounts struct {
+	Total, Imported, Inserted, Updated, Unchanged, Rejected int
+}
+
+func countsOf(r importResult) importCounts {
+	return importCounts{r.TotalRows, r.Imported, r.Inserted, r.Updated, r.Unchanged, r.Rejected}
+}
+
+func TestImportFile(t *testing.T) {
+	keysOnly := []ColumnMapping{
+		{Target: "id", Action: mapColumn, Source: "id"},
+		{Target: "name", Action: mapSkip},
+		{Target: "qty", Action: mapSkip},
+	}
+	tests := []struct {
+		name     string
+		seed     []string
+		rows     []string
+		opts     ImportOptions
+		want     []string
+		counts   importCounts
+		summary  string
+		errorHas string
+	}{
+		{
+			name:    "追加",
+			seed:    []string{`INSERT INTO items VALUES (1, 'a', 1)`},
+			rows:    []string{"2,b,2", "3,c,"},
+			want:    []string{"1:a:1", "2:b:2", "3:c:"},
+			counts:  importCounts{Total: 2, Imported: 2, Inserted: 2},
+			summary: "excel行数:2,成功导入:2",
+		},
+		{
+			name:    "按主键更新",
+			seed:    []string{`INSERT INTO items VALUES (1, 'a', 1), (2, 'b', 2)`},
+			rows:    []string{"2,B,20", "3,c,3"},
+			opts:    ImportOptions{LoadMode: loadUpsert},
+			want:    []string{"1:a:1", "2:B:20", "3:c:3"},
+			counts:  importCounts{Total: 2, Imported: 2, Inserted: 1, Updated: 1},
+			summary: "excel行数:2,成功导入:2(新增:1,更新:1)",
+		},
+		{
+			name:    "只映射键列时已存在的行不计为更新",
+			seed:    []string{`INSERT INTO items VALUES (1, 'a', 1), (2, 'b', 2)`},
+			rows:    []string{"2,B,20", "3,c,3"},
+			opts:    ImportOptions{LoadMode: loadUpsert, Mappings: keysOnly},
+			want:    []string{"1:a:1", "2:b:2", "3::"},
+			counts:  importCounts{Total: 2, Imported: 2, Inserted: 1, Unchanged: 1},
+			summary: "excel行数:2,成功导入:2(新增:1,已存在未修改:1)",
+		},
+		{
+			name:   "安全替换",
+			seed:   []string{`INSERT INTO items VALUES (1, 'a', 1), (9, 'z', 9)`},
+			rows:   []string{"2,b,2"},
+			opts:   ImportOptions{LoadMode: loadReplace},
+			want:   []string{"2:b:2"},
+			counts: importCounts{Total: 1, Imported: 1, Inserted: 1},
+		},
+		{
+			name:   "清空后导入",
+			seed:   []string{`INSERT INTO items VALUES (1, 'a', 1), (9, 'z', 9)`},
+			rows:   []string{"2,b,2"},
+			opts:   ImportOptions{LoadMode: loadTruncate},
+			want:   []string{"2:b:2"},
+			counts: importCounts{Total: 1, Imported: 1, Inserted: 1},
+		},
+		{
+			name:     "安全替换不允许跳过出错行",
+			seed:     []string{`INSERT INTO items VALUES (1, 'a', 1)`},
+			rows:     []string{"2,b,2", "3,c,-1"},
+			opts:     ImportOptions{LoadMode: loadReplace, MaxErrors: -1},
+			want:     []string{"1:a:1"},
+			counts:   importCounts{},
+			errorHas: "安全替换模式要求所有行都导入成功",
+		},
+		{
+			name:   "按条件删除后导入",
+			seed:   []string{`INSERT INTO items VALUES (1, 'a', 1), (9, 'z', 9)`},
+			rows:   []string{"2,b,2"},
+			opts:   ImportOptions{LoadMode: loadDelete, DeleteWhere: "qty > 5"},
+			want:   []string{"1:a:1", "2:b:2"},
+			counts: importCounts{Total: 1, Imported: 1, Inserted: 1},
+		},
+		{
+			name: "映射固定值并交给默认值",
+			rows: []string{"2,b,2"},
+			opts: ImportOptions{Mappings: []ColumnMapping{
+				{Target: "id", Action: mapColumn, Source: "id"},
+				{Target: "name", Action: mapDefault},
+				{Target: "qty", Action: mapConstant, Constant: "5"},
+			}},
+			want:   []string{"2::5"},
+			counts: importCounts{Total: 1, Imported: 1, Inserted: 1},
+		},
+		{
+			name:     "写入失败时报告行号",
+			rows:     []string{"2,b,2", "3,c,-1"},
+			want:     nil,
+			counts:   importCounts{Total: 2},
+			errorHas: "第3行",
+		},
+		{
+			name:   "单事务清空后导入",
+			seed:   []string{`INSERT INTO items VALUES (1, 'a', 1), (9, 'z', 9)`},
+			rows:   []string{"2,b,2"},
+			opts:   ImportOptions{LoadMode: loadTruncate, Atomic: true},
+			want:   []string{"2:b:2"},
+			counts: importCounts{Total: 1, Imported: 1, Inserted: 1},
+		},
+		{
+			name:     "单事务失败时全部回滚",
+			seed:     []string{`INSERT INTO items VALUES (1, 'a', 1)`},
+			rows:     []string{"2,b,2", "3,c,-1"},
+			opts:     ImportOptions{LoadMode: loadTruncate, Atomic: true},
+			want:     []string{"1:a:1"},
+			counts:   importCounts{Total: 2},
+			errorHas: "导入失败，已全部回滚，未写入任何数据",
+		},
+		{
+			name:     "出错行数上限不能小于 -1",
+			rows:     []string{"2,b,2"},
+			opts:     ImportOptions{MaxErrors: -2},
+			counts:   importCounts{},
+			errorHas: "允许跳过的出错行数只能是 -1(不限制)、0 或正数",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t, tt.seed...)
+			a := &App{}
+			sheets, summary, err := a.importFile(context.Background(), db, sqliteDialect{}, "items", writeTestCSV(t, tt.rows...), false, tt.opts)
+			if tt.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+					t.Fatalf("错误 = %v，应包含 %q", err, tt.errorHas)
+				}
+			} else if err != nil {
+				t.Fatalf("导入失败: %v", err)
+			}
+			if len(sheets) != 1 {
+				t.Fatalf("返回了 %d 个工作表结果", len(sheets))
+			}
+			if got := countsOf(sheets[0].importResult); got != tt.counts {
+				t.Errorf("统计 = %+v，应为 %+v", got, tt.counts)
+			}
+			if tt.summary != "" && summary != tt.summary {
+				t.Errorf("汇总 = %q，应为 %q", summary, tt.summary)
+			}
+			if got := itemRows(t, db); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("表中数据 = %v，应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportFileRejects(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxErrors int
+		rows      []string
+		want      []string
+		counts    importCounts
+		rejected  []string // 错误记录文件中的行号
+		errorHas  string
+	}{
+		{
+			name:      "不限制时跳过出错行",
+			maxErrors: -1,
+			rows:      []string{"2,b,2", "3,c,-1", "4,d,4"},
+			want:      []string{"2:b:2", "4:d:4"},
+			counts:    importCounts{Total: 3, Imported: 2, Inserted: 2, Rejected: 1},
+			rejected:  []string{"3"},
+		},
+		{
+			name:      "未超过上限",
+			maxErrors: 2,
+			rows:      []string{"2,b,-2", "3,c,-1", "4,d,4"},
+			want:      []string{"4:d:4"},
+			counts:    importCounts{Total: 3, Imported: 1, Inserted: 1, Rejected: 2},
+			rejected:  []string{"2", "3"},
+		},
+		{
+			name:      "超过上限时停止",
+			maxErrors: 1,
+			rows:      []string{"2,b,-2", "3,c,-1", "4,d,4"},
+			counts:    importCounts{Total: 3, Rejected: 2},
+			rejected:  []string{"2", "3"},
+			errorHas:  "出错行数超过上限 1",
+		},
+		{
+			name:      "不允许出错时遇到出错行即停止",
+			maxErrors: 0,
+			rows:      []string{"2,b,2", "3,c,-1"},
+			counts:    importCounts{Total: 2},
+			errorHas:  "数据库插入失败 (第3行)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t)
+			a := &App{}
+			opts := ImportOptions{MaxErrors: tt.maxErrors}
+			sheets, _, err := a.importFile(context.Background(), db, sqliteDialect{}, "items", writeTestCSV(t, tt.rows...), false, opts)
+			if tt.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+					t.Fatalf("错误 = %v，应包含 %q", err, tt.errorHas)
+				}
+			} else if err != nil {
+				t.Fatalf("导入失败: %v", err)
+			}
+			res := sheets[0].importResult
+			if got := countsOf(res); got != tt.counts {
+				t.Errorf("统计 = %+v，应为 %+v", got, tt.counts)
+			}
+			if got := itemRows(t, db); strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("表中数据 = %v，应为 %v", got, tt.want)
+			}
+
+			if len(tt.rejected) == 0 {
+				if res.RejectFile != "" {
+					t.Errorf("不应生成错误记录文件: %s", res.RejectFile)
+				}
+				return
+			}
+			t.Cleanup(func() { os.Remove(res.RejectFile) })
+			data, err := os.ReadFile(res.RejectFile)
+			if err != nil {
+				t.Fatalf("读取错误记录文件失败: %v", err)
+			}
+			records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).ReadAll()
+			if err != nil {
+				t.Fatalf("解析错误记录文件失败: %v", err)
+			}
+			if want := []string{"行号", "出错列", "错误信息", "id", "name", "qty"}; strings.Join(records[0], ",") != strings.Join(want, ",") {
+				t.Errorf("错误记录标题行 = %v，应为 %v", records[0], want)
+			}
+			var lines []string
+			for _, r := range records[1:] {
+				lines = append(lines, r[0])
+			}
+			if strings.Join(lines, ",") != strings.Join(tt.rejected, ",") {
+				t.Errorf("错误记录中的行号 = %v，应为 %v", lines, tt.rejected)
+			}
+		})
+	}
+}
+
+// TestImportFileCancel 在第一批提交后取消导入，单事务与安全替换模式应回滚全部数据，追加模式保留已提交的批次
+func TestImportFileCancel(t *testing.T) {
+	rows := make([]string, 2500)
+	for i := range rows {
+		rows[i] = fmt.Sprintf("%d,n%d,%d", i+2, i, i)
+	}
+	tests := []struct {
+		name      string
+		opts      ImportOptions
+		wantRows  int
+		committed int
+	}{
+		{name: "追加", opts: ImportOptions{}, wantRows: 1001, committed: 1000},
+		{name: "单事务", opts: ImportOptions{Atomic: true}, wantRows: 1, committed: 0},
+		{name: "安全替换", opts: ImportOptions{LoadMode: loadReplace}, wantRows: 1, committed: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openTestDB(t, `INSERT INTO items VALUES (1, 'a', 1)`)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			// 每提交一批后会报告进度，在第一次报告时取消
+			a := &App{progress: func(int, string) { cancel() }}
+			sheets, _, err := a.importFile(ctx, db, sqliteDialect{}, "items", writeTestCSV(t, rows...), false, tt.opts)
+			if err == nil || err.Error() != canceledMessage(tt.committed) {
+				t.Fatalf("错误 = %v，应为 %q", err, canceledMessage(tt.committed))
+			}
+			if sheets[0].Error != "已取消" || sheets[0].Imported != tt.committed {
+				t.Errorf("结果 = %+v，应为已取消且已导入 %d 行", sheets[0], tt.committed)
+			}
+			if n := itemCount(t, db); n != tt.wantRows {
+				t.Errorf("表中有 %d 行，应为 %d 行", n, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"gopkg.in/yaml.v3"
+)
+
+// 当前程序支持的任务文件格式版本
+const jobVersion = 1
+
+// ImportJob 导入任务文件，保存一次导入的全部设置，可在界面中加载，也可以通过 csv2o run 直接执行
+type ImportJob struct {
+	Version  int             `json:"version" yaml:"version"`
+	Name     string          `json:"name,omitempty" yaml:"name,omitempty"`
+	Profile  string          `json:"profile,omitempty" yaml:"profile,omitempty"` // 连接配置名称，留空时使用默认配置
+	Source   JobSource       `json:"source" yaml:"source"`
+	Table    string          `json:"table,omitempty" yaml:"table,omitempty"`       // 目标表，配置了 sheets 时不使用
+	Sheets   []SheetTable    `json:"sheets,omitempty" yaml:"sheets,omitempty"`     // 多个工作表分别导入到各自的表
+	Mappings []ColumnMapping `json:"mappings,omitempty" yaml:"mappings,omitempty"` // 列映射，留空时按列名自动匹配
+	Load     JobLoad         `json:"load" yaml:"load"`
+	Errors   JobErrorPolicy  `json:"errors" yaml:"errors"`
+}
+
+// JobSource 任务的数据来源
+type JobSource struct {
+	// 文件路径，可以包含 * ? [] 通配符，匹配多个文件时使用修改时间最新的一个；相对路径相对于任务文件所在目录
+	File         string `json:"file" yaml:"file"`
+	Sheet        string `json:"sheet,omitempty" yaml:"sheet,omitempty"`
+	Delimiter    string `json:"delimiter,omitempty" yaml:"delimiter,omitempty"`
+	HeaderRow    int    `json:"headerRow,omitempty" yaml:"headerRow,omitempty"`
+	DataStartRow int    `json:"dataStartRow,omitempty" yaml:"dataStartRow,omitempty"`
+	FooterRows   int    `json:"footerRows,omitempty" yaml:"footerRows,omitempty"`
+}
+
+// JobLoad 任务的写入方式
+type JobLoad struct {
+	Mode     string   `json:"mode,omitempty" yaml:"mode,omitempty"`         // append / upsert / truncate / delete / replace
+	Keys     []string `json:"keys,omitempty" yaml:"keys,omitempty"`         // upsert 使用的键列
+	Where    string   `json:"where,omitempty" yaml:"where,omitempty"`       // delete 模式的删除条件
+	Truncate bool     `json:"truncate,omitempty" yaml:"truncate,omitempty"` // 超长字符串按列长度截断
+}
+
+// JobErrorPolicy 任务的出错处理方式
+type JobErrorPolicy struct {
+	MaxErrors int  `json:"maxErrors,omitempty" yaml:"maxErrors,omitempty"` // 允许跳过的出错行数，-1 不限制
+	Atomic    bool `json:"atomic,omitempty" yaml:"atomic,omitempty"`       // 单事务导入
+}
+
+// JobSetup 加载任务文件后填入界面的设置
+type JobSetup struct {
+	Path          string        `json:"path"` // 任务文件路径，取消选择时为空
+	Name          string        `json:"name"`
+	Profile       string        `json:"profile"`
+	TableName     string        `json:"tableName"`
+	FilePath      string        `json:"filePath"`  // 匹配到的数据文件
+	FileError     string        `json:"fileError"` // 没有找到数据文件时的原因
+	TruncateChars string        `json:"truncateChars"`
+	Options       ImportOptions `json:"options"`
+}
+
+// newImportJob 根据界面中的设置生成任务
+func newImportJob(profile, tableName, filePath string, truncate bool, opts ImportOptions) ImportJob {
+	job := ImportJob{
+		Version: jobVersion,
+		Profile: profile,
+		Source: JobSource{
+			File:         filePath,
+			Sheet:        opts.SheetName,
+			Delimiter:    opts.Delimiter,
+			HeaderRow:    opts.HeaderRow,
+			DataStartRow: opts.DataStartRow,
+			FooterRows:   opts.FooterRows,
+		},
+		Table:    tableName,
+		Sheets:   opts.SheetTables,
+		Mappings: opts.Mappings,
+		Load:     JobLoad{Mode: opts.LoadMode, Keys: opts.KeyColumns, Where: opts.DeleteWhere, Truncate: truncate},
+		Errors:   JobErrorPolicy{MaxErrors: opts.MaxErrors, Atomic: opts.Atomic},
+	}
+	// 界面中与导入模式无关的输入框可能仍有内容，只保存当前模式用到的设置
+	mode, _ := normalizeLoadMode(opts.LoadMode)
+	if mode != loadUpsert {
+		job.Load.Keys = nil
+	}
+	if mode != loadDelete {
+		job.Load.Where = ""
+	}
+	if len(job.Sheets) > 0 {
+		job.Table = ""
+		job.Source.Sheet = ""
+		job.Mappings = nil
+	}
+	return job
+}
+
+// options 转换为导入引擎使用的选项
+func (j ImportJob) options() ImportOptions {
+	return ImportOptions{
+		Delimiter:    j.Source.Delimiter,
+		SheetName:    j.Source.Sheet,
+		SheetTables:  j.Sheets,
+		Mappings:     j.Mappings,
+		LoadMode:     j.Load.Mode,
+		KeyColumns:   j.Load.Keys,
+		DeleteWhere:  j.Load.Where,
+		Atomic:       j.Errors.Atomic,
+		MaxErrors:    j.Errors.MaxErrors,
+		HeaderRow:    j.Source.HeaderRow,
+		DataStartRow: j.Source.DataStartRow,
+		FooterRows:   j.Source.FooterRows,
+	}
+}
+
+// validate 检查任务的各项设置，一次列出所有问题
+func (j ImportJob) validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch {
+	case j.Version == 0:
+		add("缺少 version")
+	case j.Version > jobVersion:
+		add("version %d 高于当前程序支持的版本 %d，请升级程序", j.Version, jobVersion)
+	case j.Version < 0:
+		add("version 不正确")
+	}
+
+	if strings.TrimSpace(j.Source.File) == "" {
+		add("source.file 不能为空")
+	} else if _, err := filepath.Match(j.Source.File, ""); err != nil {
+		add("source.file 中的通配符格式不正确")
+	}
+	if _, err := parseDelimiter(j.Source.Delimiter); err != nil {
+		add("source.delimiter: %v", err)
+	}
+	headerRow := max(j.Source.HeaderRow, 1)
+	if j.Source.HeaderRow < 0 {
+		add("source.headerRow 不能为负数")
+	}
+	if j.Source.DataStartRow != 0 && j.Source.DataStartRow <= headerRow {
+		add("source.dataStartRow(%d) 必须大于标题行(%d)", j.Source.DataStartRow, headerRow)
+	}
+	if j.Source.FooterRows < 0 {
+		add("source.footerRows 不能为负数")
+	}
+
+	if len(j.Sheets) == 0 {
+		if strings.TrimSpace(j.Table) == "" {
+			add("table 不能为空")
+		}
+		checkJobMappings("mappings", j.Mappings, add)
+	} else {
+		if j.Source.Sheet != "" {
+			add("配置了 sheets 时不能再指定 source.sheet")
+		}
+		if len(j.Mappings) > 0 {
+			add("配置了 sheets 时请在各工作表中配置 mappings")
+		}
+		for i, st := range j.Sheets {
+			if strings.TrimSpace(st.Sheet) == "" {
+				add("sheets[%d].sheet 不能为空", i)
+			}
+			if strings.TrimSpace(st.Table) == "" {
+				add("sheets[%d].table 不能为空", i)
+			}
+			checkJobMappings(fmt.Sprintf("sheets[%d].mappings", i), st.Mappings, add)
+		}
+	}
+
+	mode, err := normalizeLoadMode(j.Load.Mode)
+	if err != nil {
+		add("load.mode: %v", err)
+	}
+	if mode == loadDelete {
+		if strings.TrimSpace(j.Load.Where) == "" {
+			add("load.mode 为 delete 时需要填写 load.where(如需删除全部数据请填写 1=1)")
+		} else if err := checkDeleteWhere(j.Load.Where); err != nil {
+			add("load.where: %v", err)
+		}
+	}
+	if len(j.Load.Keys) > 0 && mode != loadUpsert {
+		add("load.keys 只在 load.mode 为 upsert 时使用")
+	}
+	if err := checkMaxErrors(j.Errors.MaxErrors); err != nil {
+		add("errors.maxErrors: %v", err)
+	} else if mode == loadReplace && j.Errors.MaxErrors != 0 {
+		add("load.mode 为 replace 时 errors.maxErrors 只能为 0")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("任务文件格式错误:\n- %s", strings.Join(problems, "\n- "))
+	}
+	return nil
+}
+
+// checkJobMappings 检查列映射，问题通过 add 记录
+func checkJobMappings(path string, mappings []ColumnMapping, add func(string, ...interface{})) {
+	seen := make(map[string]bool)
+	for i, m := range mappings {
+		p := fmt.Sprintf("%s[%d]", path, i)
+		target := strings.ToUpper(strings.TrimSpace(m.Target))
+		if target == "" {
+			add("%s.target 不能为空", p)
+		} else if seen[target] {
+			add("%s.target 列 %s 重复配置", p, m.Target)
+		}
+		seen[target] = true
+
+		switch m.Action {
+		case mapColumn:
+			if strings.TrimSpace(m.Source) == "" {
+				add("%s.source 不能为空(action 为 column)", p)
+			}
+		case mapConstant, mapDefault, mapSkip:
+		case "":
+			add("%s.action 不能为空，可选 column / constant / default / skip", p)
+		default:
+			add("%s.action %s 无效，可选 column / constant / default / skip", p, m.Action)
+		}
+		for _, spec := range m.Transforms {
+			if _, err := parseTransform(spec); err != nil {
+				add("%s.transforms: %v", p, err)
+			}
+		}
+	}
+}
+
+// isJSONJob 根据扩展名判断任务文件格式，其他扩展名按内容是否以 { 开头判断
+func isJSONJob(path string, data []byte) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return true
+	case ".yaml", ".yml":
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// parseJob 解析 YAML 或 JSON 格式的任务文件并校验，未知的字段视为错误，避免拼写错误被忽略
+func parseJob(path string, data []byte) (ImportJob, error) {
+	var job ImportJob
+	if isJSONJob(path, data) {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&job); err != nil {
+			return job, fmt.Errorf("任务文件 %s 解析失败: %s", path, jsonErrorText(data, err))
+		}
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&job); err != nil && err != io.EOF {
+			return job, fmt.Errorf("任务文件 %s 解析失败: %v", path, err)
+		}
+	}
+	if err := job.validate(); err != nil {
+		return job, fmt.Errorf("%s: %v", path, err)
+	}
+	return job, nil
+}
+
+// jsonErrorText 在 JSON 解析错误中补充行号
+func jsonErrorText(data []byte, err error) string {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err.Error()
+	}
+	line := bytes.Count(data[:min(int(offset), len(data))], []byte("\n")) + 1
+	return fmt.Sprintf("第 %d 行: %v", line, err)
+}
+
+// loadJob 读取并校验任务文件
+func loadJob(path string) (ImportJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImportJob{}, fmt.Errorf("读取任务文件失败: %v", err)
+	}
+	return parseJob(path, data)
+}
+
+// writeJob 按扩展名将任务写为 JSON 或 YAML
+func writeJob(path string, job ImportJob) error {
+	if err := job.validate(); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := json.MarshalIndent(job, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	} else {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(job); err != nil {
+			return err
+		}
+		enc.Close()
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("写入任务文件失败: %v", err)
+	}
+	return nil
+}
+
+// resolveJobFile 找到任务要导入的数据文件，pattern 中有通配符时取修改时间最新的匹配文件
+func resolveJobFile(jobPath, pattern string) (string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(jobPath), pattern)
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Stat(pattern); err != nil {
+			return "", fmt.Errorf("数据文件 %s 不存在", pattern)
+		}
+		return pattern, nil
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return "", fmt.Errorf("数据文件通配符 %s 格式不正确", pattern)
+	}
+	var latest string
+	var latestInfo os.FileInfo
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		// 修改时间相同时取文件名排在后面的，如按月份命名的文件取最新月份
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) ||
+			(info.ModTime().Equal(latestInfo.ModTime()) && m > latest) {
+			latest, latestInfo = m, info
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("没有找到与 %s 匹配的数据文件", pattern)
+	}
+	return latest, nil
+}
+
+// SaveJob saves the current import setup as a YAML or JSON job file chosen in a save dialog.
+// It returns the saved path, or an empty string when the dialog is cancelled.
+func (a *App) SaveJob(profile, tableName, filePath, truncateChars string, opts ImportOptions) (string, error) {
+	job := newImportJob(profile, tableName, filePath, truncateChars == "true", opts)
+	if err := job.validate(); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(tableName)
+	if name == "" {
+		name = "import"
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "保存导入任务",
+		DefaultFilename: name + ".job.yaml",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "导入任务 (*.yaml;*.yml;*.json)", Pattern: "*.yaml;*.yml;*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	job.Name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), ".job")
+	if err := writeJob(path, job); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// OpenJob loads a job file chosen in an open dialog and returns the setup to fill into the form.
+// The returned Path is empty when the dialog is cancelled.
+func (a *App) OpenJob() (JobSetup, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "打开导入任务",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "导入任务 (*.yaml;*.yml;*.json)", Pattern: "*.yaml;*.yml;*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return JobSetup{}, err
+	}
+	job, err := loadJob(path)
+	if err != nil {
+		return JobSetup{}, err
+	}
+	setup := JobSetup{
+		Path:          path,
+		Name:          job.Name,
+		Profile:       job.Profile,
+		TableName:     job.Table,
+		TruncateChars: fmt.Sprint(job.Load.Truncate),
+		Options:       job.options(),
+	}
+	if setup.FilePath, err = resolveJobFile(path, job.Source.File); err != nil {
+		setup.FileError = err.Error()
+	}
+	return setup, nil
+}
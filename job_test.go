@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportJobValidate(t *testing.T) {
+	valid := func() ImportJob {
+		return ImportJob{
+			Version: jobVersion,
+			Source:  JobSource{File: "data/*.csv"},
+			Table:   "scott.emp",
+			Mappings: []ColumnMapping{
+				{Target: "ID", Action: mapColumn, Source: "编号"},
+				{Target: "NAME", Action: mapColumn, Source: "姓名", Transforms: []string{"upper"}},
+			},
+			Load: JobLoad{Mode: loadUpsert, Keys: []string{"ID"}},
+		}
+	}
+	tests := []struct {
+		name   string
+		modify func(j *ImportJob)
+		errors []string // 错误信息中应列出的问题，为空表示校验通过
+	}{
+		{name: "完整的任务", modify: func(j *ImportJob) {}},
+		{name: "不限制出错行数", modify: func(j *ImportJob) { j.Errors.MaxErrors = -1 }},
+		{name: "多工作表", modify: func(j *ImportJob) {
+			j.Table, j.Mappings = "", nil
+			j.Sheets = []SheetTable{{Sheet: "订单", Table: "orders"}, {Sheet: "明细", Table: "items"}}
+		}},
+		{name: "缺少版本", modify: func(j *ImportJob) { j.Version = 0 }, errors: []string{"缺少 version"}},
+		{name: "版本过高", modify: func(j *ImportJob) { j.Version = jobVersion + 1 }, errors: []string{"请升级程序"}},
+		{name: "缺少文件与表", modify: func(j *ImportJob) { j.Source.File, j.Table = " ", "" },
+			errors: []string{"source.file 不能为空", "table 不能为空"}},
+		{name: "通配符格式错误", modify: func(j *ImportJob) { j.Source.File = "data/[a.csv" }, errors: []string{"通配符格式不正确"}},
+		{name: "分隔符无效", modify: func(j *ImportJob) { j.Source.Delimiter = `"` }, errors: []string{"source.delimiter"}},
+		{name: "行号设置错误", modify: func(j *ImportJob) {
+			j.Source.HeaderRow, j.Source.DataStartRow, j.Source.FooterRows = 3, 2, -1
+		}, errors: []string{"source.dataStartRow(2) 必须大于标题行(3)", "source.footerRows 不能为负数"}},
+		{name: "导入模式无效", modify: func(j *ImportJob) { j.Load.Mode = "merge" }, errors: []string{"load.mode: 不支持的导入模式: merge"}},
+		{name: "delete 缺少条件", modify: func(j *ImportJob) { j.Load.Mode = loadDelete },
+			errors: []string{"load.mode 为 delete 时需要填写 load.where", "load.keys 只在 load.mode 为 upsert 时使用"}},
+		{name: "删除条件含多条语句", modify: func(j *ImportJob) { j.Load.Mode, j.Load.Keys, j.Load.Where = loadDelete, nil, "1=1; DROP TABLE t" },
+			errors: []string{"load.where: 删除条件中不能包含 ;"}},
+		{name: "安全替换允许跳过出错行", modify: func(j *ImportJob) { j.Load.Mode, j.Load.Keys, j.Errors.MaxErrors = loadReplace, nil, 10 },
+			errors: []string{"load.mode 为 replace 时 errors.maxErrors 只能为 0"}},
+		{name: "出错行数小于 -1", modify: func(j *ImportJob) { j.Errors.MaxErrors = -2 },
+			errors: []string{"errors.maxErrors: 允许跳过的出错行数只能是 -1(不限制)、0 或正数，当前为 -2"}},
+		{name: "列映射错误", modify: func(j *ImportJob) {
+			j.Mappings = append(j.Mappings,
+				ColumnMapping{Target: "id", Action: mapConstant},
+				ColumnMapping{Target: "QTY", Action: mapColumn},
+				ColumnMapping{Target: "", Action: "copy"},
+				ColumnMapping{Target: "PRICE", Action: mapColumn, Source: "单价", Transforms: []string{"trim"}})
+		}, errors: []string{
+			"mappings[2].target 列 id 重复配置",
+			"mappings[3].source 不能为空(action 为 column)",
+			"mappings[4].target 不能为空",
+			"mappings[4].action copy 无效",
+			"mappings[5].transforms: 不支持的转换 trim",
+		}},
+		{name: "多工作表与单表设置混用", modify: func(j *ImportJob) {
+			j.Source.Sheet = "Sheet1"
+			j.Sheets = []SheetTable{{Sheet: "", Table: "orders", Mappings: []ColumnMapping{{Target: "ID"}}}}
+		}, errors: []string{
+			"配置了 sheets 时不能再指定 source.sheet",
+			"配置了 sheets 时请在各工作表中配置 mappings",
+			"sheets[0].sheet 不能为空",
+			"sheets[0].mappings[0].action 不能为空",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := valid()
+			tt.modify(&job)
+			err := job.validate()
+			if len(tt.errors) == 0 {
+				if err != nil {
+					t.Fatalf("校验失败: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("校验通过，应报告 %v", tt.errors)
+			}
+			for _, want := range tt.errors {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("错误信息中缺少 %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// 导入模式
+const (
+	loadAppend   = "append"   // 追加
+	loadUpsert   = "upsert"   // 按键更新已存在的行，插入新行
+	loadTruncate = "truncate" // 先清空表再导入
+	loadDelete   = "delete"   // 先按条件删除再导入
+	loadReplace  = "replace"  // 导入到临时表，校验通过后在一个事务内替换目标表数据
+)
+
+func normalizeLoadMode(mode string) (string, error) {
+	mode = strings.ToLower(strings.TrimSpace(mode))
+	switch mode {
+	case "":
+		return loadAppend, nil
+	case loadAppend, loadUpsert, loadTruncate, loadDelete, loadReplace:
+		return mode, nil
+	}
+	return "", fmt.Errorf("不支持的导入模式: %s", mode)
+}
+
+// checkDeleteWhere 检查删除条件。条件由有权删除目标表数据的操作者填写，会原样拼接到
+// DELETE 语句中，这里只拒绝分号与注释，避免带入多条语句或注释掉后面的内容，
+// 不能用于过滤来自不可信来源的条件
+func checkDeleteWhere(where string) error {
+	where = strings.TrimSpace(where)
+	if where == "" {
+		return fmt.Errorf("删除后导入模式需要填写删除条件(如需删除全部数据请填写 1=1)")
+	}
+	for _, s := range []string{";", "--", "/*"} {
+		if strings.Contains(where, s) {
+			return fmt.Errorf("删除条件中不能包含 %s，只能填写一个 WHERE 条件", s)
+		}
+	}
+	return nil
+}
+
+// clearTable 在导入前清理目标表：truncate 清空整表，delete 按条件删除。
+// 删除条件按原样拼接到语句中(见 checkDeleteWhere)，连接账号的权限即为其能影响的范围
+func clearTable(ctx context.Context, db sqlExecer, d dialect, tableName, loadMode, deleteWhere string) error {
+	var stmt string
+	if loadMode == loadTruncate {
+		stmt = d.truncateSQL(tableName)
+	} else {
+		if err := checkDeleteWhere(deleteWhere); err != nil {
+			return err
+		}
+		stmt = fmt.Sprintf("DELETE FROM %s WHERE %s", tableName, strings.TrimSpace(deleteWhere))
+	}
+
+	res, err := db.ExecContext(ctx, stmt)
+	if err != nil {
+		return fmt.Errorf("清理目标表失败: %v", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && loadMode == loadDelete {
+		log.Printf("导入前已从 %s 删除 %d 行", tableName, n)
+	}
+	return nil
+}
+
+// createStagingTable 按目标表中需要导入的列创建空的临时表，返回加引号的临时表名。
+// 临时表建在目标表所在的 schema 中，避免因当前用户默认 schema 不同而建到其他位置
+func createStagingTable(ctx context.Context, db *sql.DB, d dialect, ref tableRef, columnList string) (string, error) {
+	// 名称需兼容 Oracle 30 字符的标识符长度限制
+	name := d.foldIdentifier(fmt.Sprintf("CSV2O_STG_%d", time.Now().UnixNano()%1e10))
+	staging := qualifiedName(d, tableRef{Schema: ref.Schema, Name: name})
+	if _, err := db.ExecContext(ctx, d.stagingSQL(staging, columnList, qualifiedName(d, ref))); err != nil {
+		return "", fmt.Errorf("创建临时表失败: %v", err)
+	}
+	return staging, nil
+}
+
+// dropStagingTable 删除临时表，导入取消后也要执行，因此不使用导入的 context
+func dropStagingTable(db *sql.DB, d dialect, staging string) {
+	if _, err := db.Exec(d.dropTableSQL(staging)); err != nil {
+		log.Printf("删除临时表 %s 失败: %v", staging, err)
+	}
+}
+
+// replaceFromStaging 校验临时表行数后，在一个事务内删除目标表原有数据并从临时表复制，
+// 任何一步失败都会回滚，目标表保持导入前的状态
+func replaceFromStaging(ctx context.Context, db *sql.DB, tableName, staging, columnList string, expected int) error {
+	var count int
+	if err := db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", staging)).Scan(&count); err != nil {
+		return fmt.Errorf("校验临时表失败: %v", err)
+	}
+	if count != expected {
+		return fmt.Errorf("校验临时表失败: 临时表有 %d 行，预期 %d 行", count, expected)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s", tableName)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("替换目标表数据失败: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tableName, columnList, columnList, staging)); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("替换目标表数据失败: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交替换事务失败: %v", err)
+	}
+	return nil
+}
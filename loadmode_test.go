@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeLoadMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		want     string
+		errorHas string
+	}{
+		{mode: "", want: loadAppend},
+		{mode: " Upsert ", want: loadUpsert},
+		{mode: "TRUNCATE", want: loadTruncate},
+		{mode: "delete", want: loadDelete},
+		{mode: "replace", want: loadReplace},
+		{mode: "merge", errorHas: "不支持的导入模式: merge"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeLoadMode(tt.mode)
+		if tt.errorHas != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+				t.Errorf("normalizeLoadMode(%q) 错误 = %v，应包含 %q", tt.mode, err, tt.errorHas)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeLoadMode(%q) = %q, %v，应为 %q", tt.mode, got, err, tt.want)
+		}
+	}
+}
+
+// TestClearTableCheckWhere 删除条件为空或带有多条语句、注释时不访问数据库直接报错
+func TestClearTableCheckWhere(t *testing.T) {
+	var db *sql.DB
+	tests := []struct {
+		where    string
+		errorHas string
+	}{
+		{where: "", errorHas: "需要填写删除条件"},
+		{where: "  ", errorHas: "需要填写删除条件"},
+		{where: "1=1; DROP TABLE T", errorHas: "不能包含 ;"},
+		{where: "ID = 1 -- AND BATCH = 2", errorHas: "不能包含 --"},
+		{where: "ID = 1 /* x */", errorHas: "不能包含 /*"},
+	}
+	for _, tt := range tests {
+		err := clearTable(context.Background(), db, oracleDialect{}, "T", loadDelete, tt.where)
+		if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+			t.Errorf("clearTable(%q) 错误 = %v，应包含 %q", tt.where, err, tt.errorHas)
+		}
+	}
+	if err := checkDeleteWhere(" BATCH_DATE = DATE '2024-01-01' "); err != nil {
+		t.Errorf("checkDeleteWhere 失败: %v", err)
+	}
+}
+
+// TestCreateStagingTableSchema 临时表应建在目标表所在的 schema 中
+func TestCreateStagingTableSchema(t *testing.T) {
+	db := openTestDB(t)
+	// ATTACH 只对当前连接有效，限制为一个连接
+	db.SetMaxOpenConns(1)
+	other := filepath.Join(t.TempDir(), "other.db")
+	for _, stmt := range []string{`ATTACH DATABASE '` + other + `' AS other`, `CREATE TABLE other.orders (id INTEGER, amount REAL)`} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("执行 %s 失败: %v", stmt, err)
+		}
+	}
+
+	d := sqliteDialect{}
+	staging, err := createStagingTable(context.Background(), db, d, tableRef{Schema: "other", Name: "orders"}, `"id"`)
+	if err != nil {
+		t.Fatalf("创建临时表失败: %v", err)
+	}
+	defer dropStagingTable(db, d, staging)
+	if !strings.HasPrefix(staging, `"other"."CSV2O_STG_`) {
+		t.Errorf("临时表名 = %s，应在 other 中", staging)
+	}
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM other.sqlite_master WHERE name LIKE 'CSV2O_STG_%'`).Scan(&n); err != nil || n != 1 {
+		t.Errorf("other 中的临时表数量 = %d, %v，应为 1", n, err)
+	}
+}
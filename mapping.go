@@ -0,0 +1,254 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 列映射的取值方式
+const (
+	mapColumn   = "column"   // 取文件中的某一列
+	mapConstant = "constant" // 固定值
+	mapDefault  = "default"  // 交给数据库默认值
+	mapSkip     = "skip"     // 不导入该列
+)
+
+// ColumnMapping 数据库列与数据来源的对应关系
+type ColumnMapping struct {
+	Target     string   `json:"target" yaml:"target"`                             // 数据库列名
+	Action     string   `json:"action" yaml:"action"`                             // column / constant / default / skip
+	Source     string   `json:"source" yaml:"source,omitempty"`                   // 文件列名，action 为 column 时有效
+	Constant   string   `json:"constant" yaml:"constant,omitempty"`               // 固定值，action 为 constant 时有效
+	Transforms []string `json:"transforms,omitempty" yaml:"transforms,omitempty"` // 写入前依次对值做的转换，写法见 parseTransform
+}
+
+// boundColumn 已与文件标题行绑定的目标列
+type boundColumn struct {
+	TableColumnInfo
+	Action     string
+	SourceIdx  int
+	Constant   string
+	transforms []func(string) string
+}
+
+// value 取出当前行中该列对应的值，并依次做配置的转换
+func (c boundColumn) value(row []string) string {
+	v := c.Constant
+	if c.Action != mapConstant {
+		v = ""
+		if c.SourceIdx < len(row) {
+			v = strings.TrimSpace(row[c.SourceIdx])
+		}
+	}
+	for _, t := range c.transforms {
+		v = t(v)
+	}
+	return v
+}
+
+// parseTransform 解析一个转换，支持:
+//
+//	upper / lower        转为大写 / 小写
+//	remove:文本          删除值中所有的该文本，如 remove:, 去掉千分位
+//	default:值           值为空时使用该值
+//	date:格式            按格式解析日期，如 date:dd.MM.yyyy，格式中可用 yyyy yy MMM MM M dd d HH H mm ss
+func parseTransform(spec string) (func(string) string, error) {
+	name, arg, hasArg := strings.Cut(strings.TrimSpace(spec), ":")
+	switch strings.ToLower(name) {
+	case "upper":
+		if !hasArg {
+			return strings.ToUpper, nil
+		}
+	case "lower":
+		if !hasArg {
+			return strings.ToLower, nil
+		}
+	case "remove":
+		if arg != "" {
+			return func(v string) string { return strings.ReplaceAll(v, arg, "") }, nil
+		}
+	case "default":
+		if hasArg {
+			return func(v string) string {
+				if v == "" {
+					return arg
+				}
+				return v
+			}, nil
+		}
+	case "date":
+		layout, err := dateLayout(arg)
+		if err != nil {
+			return nil, err
+		}
+		// 不符合格式的值原样保留，写入或校验时按日期格式不规范报错
+		return func(v string) string {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.Format("2006-01-02 15:04:05")
+			}
+			return v
+		}, nil
+	default:
+		return nil, fmt.Errorf("不支持的转换 %s", spec)
+	}
+	return nil, fmt.Errorf("转换 %s 的参数不正确", spec)
+}
+
+// 日期格式中的占位符与 Go 时间格式的对应关系，较长的写在前面优先匹配
+var dateTokens = []struct{ token, layout string }{
+	{"yyyy", "2006"}, {"yy", "06"}, {"MMM", "Jan"}, {"MM", "01"}, {"M", "1"},
+	{"dd", "02"}, {"d", "2"}, {"HH", "15"}, {"H", "15"}, {"mm", "04"}, {"ss", "05"},
+}
+
+// dateLayout 将 yyyy-MM-dd 形式的日期格式转换为 Go 的时间格式。
+// 占位符以外只允许分隔符，避免其中的数字或字母被当作 Go 时间格式解释
+func dateLayout(format string) (string, error) {
+	if strings.TrimSpace(format) == "" {
+		return "", fmt.Errorf("日期格式不能为空")
+	}
+	var b strings.Builder
+next:
+	for rest := format; rest != ""; {
+		for _, t := range dateTokens {
+			if strings.HasPrefix(rest, t.token) {
+				b.WriteString(t.layout)
+				rest = rest[len(t.token):]
+				continue next
+			}
+		}
+		r, size := utf8.DecodeRuneInString(rest)
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return "", fmt.Errorf("日期格式 %s 中的 %c 无法识别", format, r)
+		}
+		b.WriteString(rest[:size])
+		rest = rest[size:]
+	}
+	return b.String(), nil
+}
+
+// normalizeColumnName 去掉大小写、空白、下划线和连字符的差异，用于模糊匹配列名
+func normalizeColumnName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer(" ", "", "_", "", "-", "", "\t", "").Replace(name)
+}
+
+// proposeMapping 按列名为每个数据库列推荐数据来源，找不到对应列时交给数据库默认值
+func proposeMapping(excelHeaders, dbColumns []string) []ColumnMapping {
+	mappings := make([]ColumnMapping, 0, len(dbColumns))
+	used := make(map[int]bool)
+	for _, col := range dbColumns {
+		m := ColumnMapping{Target: col, Action: mapDefault}
+		idx := -1
+		// 先按名称精确匹配(不区分大小写)，再按归一化后的名称匹配
+		for i, header := range excelHeaders {
+			if !used[i] && strings.EqualFold(strings.TrimSpace(header), strings.TrimSpace(col)) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			for i, header := range excelHeaders {
+				if !used[i] && normalizeColumnName(header) != "" && normalizeColumnName(header) == normalizeColumnName(col) {
+					idx = i
+					break
+				}
+			}
+		}
+		if idx >= 0 {
+			used[idx] = true
+			m.Action = mapColumn
+			m.Source = strings.TrimSpace(excelHeaders[idx])
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings
+}
+
+// bindColumns 根据映射配置将表的每一列与文件标题行绑定。
+// 未配置映射时按列名自动匹配，且要求除有默认值的列、自增列和虚拟列以外的所有列都能在文件中找到。
+func bindColumns(dbCols []TableColumnInfo, excelHeaders []string, mappings []ColumnMapping) ([]boundColumn, error) {
+	explicit := len(mappings) > 0
+	if !explicit {
+		names := make([]string, len(dbCols))
+		for i, c := range dbCols {
+			names[i] = c.ColumnName
+		}
+		mappings = proposeMapping(excelHeaders, names)
+	}
+
+	byTarget := make(map[string]ColumnMapping)
+	for _, m := range mappings {
+		key := strings.ToUpper(strings.TrimSpace(m.Target))
+		if key == "" {
+			continue
+		}
+		if _, dup := byTarget[key]; dup {
+			return nil, fmt.Errorf("列映射配置错误: 目标列 %s 重复配置", m.Target)
+		}
+		byTarget[key] = m
+	}
+
+	known := make(map[string]bool)
+	for _, c := range dbCols {
+		known[strings.ToUpper(c.ColumnName)] = true
+	}
+	for key, m := range byTarget {
+		if !known[key] {
+			return nil, fmt.Errorf("列映射配置错误: 表中不存在列 %s", m.Target)
+		}
+	}
+
+	var bound []boundColumn
+	var unmatched []string
+	for _, c := range dbCols {
+		m, ok := byTarget[strings.ToUpper(c.ColumnName)]
+		if !ok {
+			m = ColumnMapping{Target: c.ColumnName, Action: mapDefault}
+		}
+		b := boundColumn{TableColumnInfo: c, Action: m.Action, SourceIdx: -1, Constant: m.Constant}
+		for _, spec := range m.Transforms {
+			t, err := parseTransform(spec)
+			if err != nil {
+				return nil, fmt.Errorf("列映射配置错误: 列 %s: %v", c.ColumnName, err)
+			}
+			b.transforms = append(b.transforms, t)
+		}
+		switch m.Action {
+		case mapColumn:
+			for i, header := range excelHeaders {
+				if strings.EqualFold(strings.TrimSpace(header), strings.TrimSpace(m.Source)) {
+					b.SourceIdx = i
+					break
+				}
+			}
+			if b.SourceIdx < 0 {
+				return nil, fmt.Errorf("列映射配置错误: 文件中不存在列 %s (目标列 %s)", m.Source, c.ColumnName)
+			}
+		case mapConstant, mapSkip:
+		case mapDefault, "":
+			b.Action = mapDefault
+			// 自动匹配时，有默认值的列、自增列和虚拟列可以不在文件中
+			if !explicit && !c.HasDefault && !c.Identity && !c.Virtual {
+				unmatched = append(unmatched, c.ColumnName)
+			}
+		default:
+			return nil, fmt.Errorf("列映射配置错误: 列 %s 的映射方式 %s 无效", c.ColumnName, m.Action)
+		}
+		// 虚拟列的值由数据库计算，不能写入
+		if c.Virtual && (b.Action == mapColumn || b.Action == mapConstant) {
+			if explicit {
+				return nil, fmt.Errorf("列映射配置错误: 列 %s 是虚拟列，不能写入", c.ColumnName)
+			}
+			b.Action = mapSkip
+		}
+		bound = append(bound, b)
+	}
+
+	if len(unmatched) > 0 {
+		return nil, fmt.Errorf("字段匹配失败: 缺少 %d 个必需字段 (%s)，可在字段对比中配置列映射", len(unmatched), strings.Join(unmatched, ", "))
+	}
+	return bound, nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestProposeMapping(t *testing.T) {
+	headers := []string{" 订单编号 ", "Customer Name", "order-date", "AMOUNT", "amount"}
+	got := proposeMapping(headers, []string{"订单编号", "CUSTOMER_NAME", "ORDER_DATE", "amount", "REMARK"})
+	want := []ColumnMapping{
+		{Target: "订单编号", Action: mapColumn, Source: "订单编号"},
+		{Target: "CUSTOMER_NAME", Action: mapColumn, Source: "Customer Name"},
+		{Target: "ORDER_DATE", Action: mapColumn, Source: "order-date"},
+		{Target: "amount", Action: mapColumn, Source: "AMOUNT"},
+		{Target: "REMARK", Action: mapDefault},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("映射 = %+v，应为 %+v", got, want)
+	}
+}
+
+func TestBindColumns(t *testing.T) {
+	cols := []TableColumnInfo{{ColumnName: "ID"}, {ColumnName: "NAME"}, {ColumnName: "SOURCE"}}
+	headers := []string{"编号", "name", "备注"}
+	tests := []struct {
+		name     string
+		headers  []string
+		mappings []ColumnMapping
+		want     []string // 每列的 取值方式:文件列序号或固定值
+		errorHas string
+	}{
+		{
+			name:    "按列名自动匹配",
+			headers: []string{"source", "Name", "id"},
+			want:    []string{"column:2", "column:1", "column:0"},
+		},
+		{
+			name:     "自动匹配缺少列",
+			headers:  headers,
+			errorHas: "缺少 2 个必需字段 (ID, SOURCE)",
+		},
+		{
+			name:    "显式映射",
+			headers: headers,
+			mappings: []ColumnMapping{
+				{Target: "id", Action: mapColumn, Source: "编号"},
+				{Target: "SOURCE", Action: mapConstant, Constant: "手工导入"},
+			},
+			want: []string{"column:0", "default:", "constant:手工导入"},
+		},
+		{
+			name:     "文件中不存在的列",
+			headers:  headers,
+			mappings: []ColumnMapping{{Target: "ID", Action: mapColumn, Source: "代码"}},
+			errorHas: "文件中不存在列 代码",
+		},
+		{
+			name:     "表中不存在的列",
+			headers:  headers,
+			mappings: []ColumnMapping{{Target: "CODE", Action: mapSkip}},
+			errorHas: "表中不存在列 CODE",
+		},
+		{
+			name:     "重复配置",
+			headers:  headers,
+			mappings: []ColumnMapping{{Target: "ID", Action: mapSkip}, {Target: "id", Action: mapDefault}},
+			errorHas: "目标列 id 重复配置",
+		},
+		{
+			name:     "映射方式无效",
+			headers:  headers,
+			mappings: []ColumnMapping{{Target: "ID", Action: "copy"}},
+			errorHas: "映射方式 copy 无效",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound, err := bindColumns(cols, tt.headers, tt.mappings)
+			if tt.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+					t.Fatalf("错误 = %v，应包含 %q", err, tt.errorHas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("绑定失败: %v", err)
+			}
+			var got []string
+			for _, b := range bound {
+				switch b.Action {
+				case mapColumn:
+					got = append(got, "column:"+strconv.Itoa(b.SourceIdx))
+				default:
+					got = append(got, b.Action+":"+b.Constant)
+				}
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("绑定结果 = %v，应为 %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	tests := []struct {
+		format   string
+		want     string
+		errorHas string
+	}{
+		{format: "yyyy-MM-dd", want: "2006-01-02"},
+		{format: "dd.MM.yyyy HH:mm:ss", want: "02.01.2006 15:04:05"},
+		{format: "d/M/yy", want: "2/1/06"},
+		{format: "dd-MMM-yyyy", want: "02-Jan-2006"},
+		{format: "yyyy年MM月dd日", errorHas: "中的 年 无法识别"},
+		{format: "yyyy-MM-ddT", errorHas: "中的 T 无法识别"},
+		{format: "yyyy2MM", errorHas: "中的 2 无法识别"},
+		{format: " ", errorHas: "不能为空"},
+	}
+	for _, tt := range tests {
+		got, err := dateLayout(tt.format)
+		if tt.errorHas != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+				t.Errorf("dateLayout(%q) 错误 = %v，应包含 %q", tt.format, err, tt.errorHas)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("dateLayout(%q) = %q, %v，应为 %q", tt.format, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	tests := []struct {
+		spec     string
+		in       string
+		want     string
+		errorHas string
+	}{
+		{spec: "upper", in: "abc", want: "ABC"},
+		{spec: " LOWER ", in: "AbC", want: "abc"},
+		{spec: "remove:,", in: "1,234,567", want: "1234567"},
+		{spec: "default:N/A", in: "", want: "N/A"},
+		{spec: "default:N/A", in: "x", want: "x"},
+		{spec: "default:", in: "", want: ""},
+		{spec: "date:dd.MM.yyyy", in: "31.12.2024", want: "2024-12-31 00:00:00"},
+		{spec: "date:d/M/yyyy H:mm", in: "5/3/2024 9:07", want: "2024-03-05 09:07:00"},
+		{spec: "date:dd-MMM-yy", in: "01-Feb-24", want: "2024-02-01 00:00:00"},
+		// 不符合格式的值原样保留，由写入或校验时报错
+		{spec: "date:dd.MM.yyyy", in: "2024-12-31", want: "2024-12-31"},
+		{spec: "upper:x", errorHas: "参数不正确"},
+		{spec: "remove:", errorHas: "参数不正确"},
+		{spec: "default", errorHas: "参数不正确"},
+		{spec: "date:", errorHas: "不能为空"},
+		{spec: "date:yyyy-MM-ddX", errorHas: "中的 X 无法识别"},
+		{spec: "trim", errorHas: "不支持的转换"},
+	}
+	for _, tt := range tests {
+		fn, err := parseTransform(tt.spec)
+		if tt.errorHas != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+				t.Errorf("parseTransform(%q) 错误 = %v，应包含 %q", tt.spec, err, tt.errorHas)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTransform(%q) 失败: %v", tt.spec, err)
+			continue
+		}
+		if got := fn(tt.in); got != tt.want {
+			t.Errorf("%s(%q) = %q，应为 %q", tt.spec, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+// mssqlDialect SQL Server，使用 TDS 批量复制协议写入
+type mssqlDialect struct{ baseDialect }
+
+func (mssqlDialect) name() string { return "SQL Server" }
+
+// open 中 serviceName 为数据库名，connectionType 为 encrypt/trustServerCertificate 连接选项
+func (mssqlDialect) open(ctx context.Context, p connParams) (*sql.DB, error) {
+	dbName := strings.TrimSpace(p.ServiceName)
+	if dbName == "" {
+		return nil, fmt.Errorf("SQL Server 需要提供数据库名")
+	}
+	dsn, err := mssqlDSN(p.Host, p.Port, p.Username, p.Password, dbName, p.ConnectionType)
+	if err != nil {
+		return nil, err
+	}
+	return openDB(ctx, "sqlserver", dsn)
+}
+
+func (mssqlDialect) describe(p connParams) string {
+	return fmt.Sprintf("SQL Server: %s:%s/%s", p.Host, p.Port, p.ServiceName)
+}
+
+func (mssqlDialect) quote(ident string) string {
+	return "[" + strings.ReplaceAll(ident, "]", "]]") + "]"
+}
+
+// mssqlTableID 按 (schema, 表名) 参数得到表的 object_id
+const mssqlTableID = `OBJECT_ID(QUOTENAME(@p1) + '.' + QUOTENAME(@p2))`
+
+// resolveTable 中未指定 schema 的表名使用登录用户的默认 schema
+func (d mssqlDialect) resolveTable(ctx context.Context, db *sql.DB, name string) (tableRef, error) {
+	schema, table, err := splitTableName(d, name)
+	if err != nil {
+		return tableRef{}, err
+	}
+	var s, t sql.NullString
+	err = db.QueryRowContext(ctx, `SELECT OBJECT_SCHEMA_NAME(OBJECT_ID(@p1)), OBJECT_NAME(OBJECT_ID(@p1))`,
+		qualifiedName(d, tableRef{Schema: schema, Name: table})).Scan(&s, &t)
+	if err != nil {
+		return tableRef{}, fmt.Errorf("查询表 [%s] 失败: %v", name, err)
+	}
+	if !t.Valid {
+		return tableRef{}, fmt.Errorf("表 [%s] 不存在或无权限访问", name)
+	}
+	return tableRef{Schema: s.String, Name: t.String}, nil
+}
+
+func (mssqlDialect) columnNamesQuery() string {
+	return `
+SELECT name
+FROM sys.columns
+WHERE object_id = ` + mssqlTableID + `
+ORDER BY column_id`
+}
+
+func (mssqlDialect) columnsQuery(context.Context, *sql.DB) (string, error) {
+	return mssqlColumnsQuery, nil
+}
+
+func (mssqlDialect) scanColumn(rows *sql.Rows) (TableColumnInfo, error) {
+	var r columnRow
+	if err := rows.Scan(append(r.fields(), &r.flag1, &r.flag2, &r.comment)...); err != nil {
+		return TableColumnInfo{}, err
+	}
+	c := r.info()
+	c.Identity = r.flag1 == "YES"
+	c.Virtual = r.flag2 == "YES"
+	// SQL Server 的 timestamp 即 rowversion，是由数据库自动生成的二进制版本号，不能写入。
+	// DataType 保留数据库报告的名称，类型说明与数据类别按 rowversion 处理
+	if strings.EqualFold(c.DataType, "timestamp") {
+		c.Virtual = true
+	}
+	c.ColumnType = mssqlColumnType(c)
+	return c, nil
+}
+
+func (mssqlDialect) keyColumnsQuery() string {
+	return `SELECT c.name, CASE WHEN i.is_primary_key = 1 THEN 'P' ELSE 'U' END
+				  FROM sys.indexes i
+				  JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+				  JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+				  WHERE i.object_id = ` + mssqlTableID + ` AND i.is_unique = 1 AND ic.is_included_column = 0`
+}
+
+func (mssqlDialect) primaryKeyQuery() string {
+	return `SELECT c.name
+				  FROM sys.indexes i
+				  JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+				  JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+				  WHERE i.object_id = ` + mssqlTableID + ` AND i.is_primary_key = 1
+				  ORDER BY ic.key_ordinal`
+}
+
+// schemasQuery 排除系统 schema 与数据库角色对应的 schema
+func (mssqlDialect) schemasQuery() string {
+	return `SELECT name FROM sys.schemas
+				  WHERE schema_id < 16384 AND name NOT IN ('sys', 'INFORMATION_SCHEMA', 'guest')
+				  ORDER BY name`
+}
+
+func (mssqlDialect) currentSchemaQuery() string { return `SELECT SCHEMA_NAME()` }
+
+// tablesQuery 中行数取自 sys.partitions 的堆或聚集索引，注释为 MS_Description 扩展属性
+func (mssqlDialect) tablesQuery() string {
+	return `SELECT o.name, CASE o.type WHEN 'V' THEN 'VIEW' ELSE 'TABLE' END,
+				         (SELECT SUM(p.rows) FROM sys.partitions p WHERE p.object_id = o.object_id AND p.index_id IN (0, 1)),
+				         CAST(ep.value AS nvarchar(4000)), NULL
+				  FROM sys.objects o
+				  LEFT JOIN sys.extended_properties ep ON ep.class = 1 AND ep.major_id = o.object_id AND ep.minor_id = 0 AND ep.name = 'MS_Description'
+				  WHERE o.schema_id = SCHEMA_ID(@p1) AND o.type IN ('U', 'V')
+				  ORDER BY o.name`
+}
+
+func (mssqlDialect) placeholder(c boundColumn, n int, enableTruncation bool) string {
+	return fmt.Sprintf("@p%d", n)
+}
+
+// convert 批量复制无法在写入时调用函数，截断在这里完成，且要求日期与数值为对应的 Go 类型
+func (mssqlDialect) convert(c boundColumn, val string, enableTruncation bool) (interface{}, error) {
+	switch c.kind() {
+	case kindDate:
+		if val == "" {
+			return nil, nil
+		}
+		t, err := tryParseDate(val)
+		if err != nil {
+			return nil, fmt.Errorf("日期格式不规范: %s", val)
+		}
+		return t, nil
+	case kindInteger, kindDecimal, kindFloat:
+		if val == "" {
+			return nil, nil
+		}
+		return mssqlNumber(c.kind(), val)
+	}
+	return convertValue(c, val, enableTruncation)
+}
+
+// upsertSQL 使用 MERGE，UPDATE SET 中目标列不能带别名
+func (mssqlDialect) upsertSQL(table, insertSQL string, names, placeholders []string, keyIdx []int) (string, string) {
+	// SQL Server 的 MERGE 必须以分号结束
+	return mergeSQL(table, names, placeholders, keyIdx, "", "") + ";", ""
+}
+
+// SQL Server 不支持多列 IN 值列表
+func (mssqlDialect) rowValueIn() bool { return false }
+
+// writeBatch 追加时使用批量复制；MERGE 不允许同一语句中出现重复的键，
+// 批量复制也不支持部分类型，这两种情况逐行执行
+func (mssqlDialect) writeBatch(ctx context.Context, tx *sql.Tx, b *batch) error {
+	if b.upsert || !mssqlBulkSupported(b.cols) {
+		return execRows(ctx, tx, b)
+	}
+	return mssqlCopy(ctx, tx, b.table, b.columns, b.buffers)
+}
+
+// SQL Server 的部分错误会中止整批语句，每一行都需要单独的保存点
+func (mssqlDialect) rowSavepoints() bool { return true }
+
+func (mssqlDialect) savepointSQL(name string) string  { return "SAVE TRANSACTION " + name }
+func (mssqlDialect) rollbackToSQL(name string) string { return "ROLLBACK TRANSACTION " + name }
+
+// errorColumn 从 515(不能为 NULL) 与 2628(值过长) 错误消息的 column 'xxx' 中取出列名
+func (mssqlDialect) errorColumn(err error) string {
+	var msErr mssql.Error
+	if !errors.As(err, &msErr) || (msErr.Number != 515 && msErr.Number != 2628) {
+		return ""
+	}
+	if m := quotedColumn.FindStringSubmatch(msErr.Message); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// SQL Server 不支持 CREATE TABLE AS SELECT
+func (mssqlDialect) stagingSQL(staging, columnList, table string) string {
+	return fmt.Sprintf("SELECT %s INTO %s FROM %s WHERE 1 = 0", columnList, staging, table)
+}
+
+// integerRange 中 SQL Server 的 tinyint 为 0~255
+func (mssqlDialect) integerRange(c TableColumnInfo) (*big.Int, *big.Int, bool) {
+	return integerRange(c, true)
+}
+
+// SQL Server 不接受带小数的文本写入整数列
+func (mssqlDialect) integerFraction() (string, string) {
+	return "error", "整数列不能包含小数"
+}
+
+func (mssqlDialect) inferType(s *columnStats) string {
+	switch s.kind() {
+	case kindInteger:
+		switch {
+		case s.intDigits <= 9:
+			return "INT"
+		case s.intDigits <= 18:
+			return "BIGINT"
+		}
+		return fmt.Sprintf("DECIMAL(%d,0)", min(s.intDigits+2, 38))
+	case kindDecimal:
+		return fmt.Sprintf("DECIMAL(%d,%d)", min(s.precision(), 38), s.scale)
+	case kindBoolean:
+		return "BIT"
+	case kindDate:
+		if s.hasTime {
+			return "DATETIME2"
+		}
+		return "DATE"
+	}
+	// 使用 Unicode 字符类型保存中文
+	if length := roundUpLength(s.maxChars); length <= 4000 {
+		return fmt.Sprintf("NVARCHAR(%d)", length)
+	}
+	return "NVARCHAR(MAX)"
+}
+
+func (mssqlDialect) identifierLimit() (int, bool) { return 128, false }
+
+// SQL Server 独有的保留字(如 END、IDENTITY、TOP)，KEY、FILE 等已在 reservedWords 中
+var mssqlReservedWords = map[string]bool{
+	"AUTHORIZATION": true, "BACKUP": true, "BEGIN": true, "BREAK": true, "BROWSE": true, "BULK": true,
+	"CASCADE": true, "CHECKPOINT": true, "CLOSE": true, "CLUSTERED": true, "COALESCE": true, "COLLATE": true,
+	"COMMIT": true, "COMPUTE": true, "CONSTRAINT": true, "CONTAINS": true, "CONTAINSTABLE": true,
+	"CONTINUE": true, "CONVERT": true, "CURRENT_DATE": true, "CURRENT_TIME": true, "CURRENT_TIMESTAMP": true,
+	"CURRENT_USER": true, "CURSOR": true, "DATABASE": true, "DBCC": true, "DEALLOCATE": true, "DECLARE": true,
+	"DENY": true, "DISK": true, "DISTRIBUTED": true, "DUMP": true, "END": true, "ERRLVL": true, "ESCAPE": true,
+	"EXCEPT": true, "EXEC": true, "EXECUTE": true, "EXIT": true, "EXTERNAL": true, "FETCH": true,
+	"FILLFACTOR": true, "FREETEXT": true, "FREETEXTTABLE": true, "FULL": true, "FUNCTION": true, "GOTO": true,
+	"HOLDLOCK": true, "IDENTITY": true, "IDENTITYCOL": true, "IDENTITY_INSERT": true, "IF": true, "KILL": true,
+	"LINENO": true, "LOAD": true, "MERGE": true, "NATIONAL": true, "NOCHECK": true, "NONCLUSTERED": true,
+	"NULLIF": true, "OFF": true, "OFFSETS": true, "OPEN": true, "OPENDATASOURCE": true, "OPENQUERY": true,
+	"OPENROWSET": true, "OPENXML": true, "OVER": true, "PERCENT": true, "PIVOT": true, "PLAN": true,
+	"PRECISION": true, "PRINT": true, "PROC": true, "PROCEDURE": true, "RAISERROR": true, "READTEXT": true,
+	"RECONFIGURE": true, "REPLICATION": true, "RESTORE": true, "RESTRICT": true, "RETURN": true, "REVERT": true,
+	"ROLLBACK": true, "ROWCOUNT": true, "ROWGUIDCOL": true, "RULE": true, "SAVE": true, "SECURITYAUDIT": true,
+	"SESSION_USER": true, "SETUSER": true, "SHUTDOWN": true, "SOME": true, "STATISTICS": true,
+	"SYSTEM_USER": true, "TABLESAMPLE": true, "TEXTSIZE": true, "TOP": true, "TRAN": true, "TRANSACTION": true,
+	"TRUNCATE": true, "TRY_CONVERT": true, "TSEQUAL": true, "UNPIVOT": true, "UPDATETEXT": true, "VARYING": true,
+	"WAITFOR": true, "WHILE": true, "WITHIN": true, "WRITETEXT": true,
+}
+
+func (mssqlDialect) isReserved(id string) bool {
+	id = strings.ToUpper(id)
+	return reservedWords[id] || mssqlReservedWords[id]
+}
+
+// SQL Server 支持的 encrypt 取值
+var mssqlEncryptModes = map[string]bool{
+	"disable": true, "false": true, "true": true, "strict": true,
+}
+
+// mssqlDSN 生成使用 SQL 身份验证的 SQL Server 连接串。
+// options 为 "encrypt=true&trustServerCertificate=false" 形式的连接选项，encrypt 为空时使用 true
+func mssqlDSN(host, port, username, password, dbName, options string) (string, error) {
+	opts, err := url.ParseQuery(options)
+	if err != nil {
+		return "", fmt.Errorf("无效的连接选项: %s", options)
+	}
+	encrypt := strings.ToLower(strings.TrimSpace(opts.Get("encrypt")))
+	if encrypt == "" {
+		encrypt = "true"
+	}
+	if !mssqlEncryptModes[encrypt] {
+		return "", fmt.Errorf("不支持的 encrypt 选项: %s", encrypt)
+	}
+	trust := strings.ToLower(strings.TrimSpace(opts.Get("trustServerCertificate"))) == "true"
+
+	query := url.Values{}
+	query.Set("database", dbName)
+	query.Set("encrypt", encrypt)
+	query.Set("TrustServerCertificate", fmt.Sprint(trust))
+	u := url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(username, password),
+		Host:     net.JoinHostPort(strings.Trim(host, "[]"), port), // IPv6 地址需要加方括号
+		RawQuery: query.Encode(),
+	}
+	return u.String(), nil
+}
+
+// mssqlColumnsQuery 从 INFORMATION_SCHEMA.COLUMNS 与 sys.columns 读取 (schema, 表名) 的列定义。
+// nvarchar/nchar 的长度按字符计算
+const mssqlColumnsQuery = `SELECT c.COLUMN_NAME, c.DATA_TYPE, COALESCE(c.CHARACTER_MAXIMUM_LENGTH, 0), c.IS_NULLABLE,
+			         CASE WHEN c.NUMERIC_PRECISION_RADIX = 10 THEN CAST(c.NUMERIC_PRECISION AS int) ELSE 0 END, COALESCE(CAST(c.NUMERIC_SCALE AS int), -1),
+			         COALESCE(c.CHARACTER_MAXIMUM_LENGTH, 0), COALESCE(c.CHARACTER_OCTET_LENGTH, 0),
+			         CASE WHEN c.DATA_TYPE IN ('varchar', 'char') THEN 'B' ELSE 'C' END,
+			         c.COLUMN_DEFAULT, CASE WHEN sc.is_identity = 1 THEN 'YES' ELSE 'NO' END, CASE WHEN sc.is_computed = 1 THEN 'YES' ELSE 'NO' END,
+			         CAST(ep.value AS nvarchar(4000))
+			  FROM sys.columns sc
+			  JOIN sys.objects o ON o.object_id = sc.object_id
+			  JOIN INFORMATION_SCHEMA.COLUMNS c ON c.TABLE_SCHEMA = SCHEMA_NAME(o.schema_id) AND c.TABLE_NAME = o.name AND c.COLUMN_NAME = sc.name
+			  LEFT JOIN sys.extended_properties ep ON ep.major_id = sc.object_id AND ep.minor_id = sc.column_id AND ep.class = 1 AND ep.name = 'MS_Description'
+			  WHERE sc.object_id = ` + mssqlTableID + `
+			  ORDER BY c.ORDINAL_POSITION`
+
+// mssqlColumnType 拼出 SQL Server 列的完整类型，如 nvarchar(50)、decimal(10,2)、varchar(max)
+func mssqlColumnType(c TableColumnInfo) string {
+	t := strings.ToLower(c.DataType)
+	switch t {
+	case "varchar", "char", "nvarchar", "nchar", "varbinary", "binary":
+		if c.CharLength < 0 {
+			return t + "(max)"
+		}
+		return fmt.Sprintf("%s(%d)", t, c.CharLength)
+	case "decimal", "numeric":
+		return fmt.Sprintf("%s(%d,%d)", t, c.Precision, max(c.Scale, 0))
+	case "timestamp":
+		return "rowversion"
+	}
+	return t
+}
+
+// mssqlCopy 使用 TDS 批量复制协议将缓冲区中的数据写入 table
+func mssqlCopy(ctx context.Context, tx *sql.Tx, table string, columns []string, columnBuffers [][]interface{}) error {
+	// 保留文件中的空值，不使用列的默认值
+	stmt, err := tx.PrepareContext(ctx, mssql.CopyIn(table, mssql.BulkOptions{KeepNulls: true}, columns...))
+	if err != nil {
+		return err
+	}
+	count := len(columnBuffers[0])
+	row := make([]interface{}, len(columns))
+	for k := 0; k < count; k++ {
+		for i := range columns {
+			row[i] = columnBuffers[i][k]
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	// 不带参数的 Exec 发送缓冲的数据，数据错误在此时返回
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// mssqlBulkSupported 判断批量复制协议能否写入这些列，
+// money、uniqueidentifier 等类型不支持以文本写入，需要改用逐行 INSERT
+func mssqlBulkSupported(cols []boundColumn) bool {
+	for _, c := range cols {
+		switch strings.ToLower(c.DataType) {
+		case "money", "smallmoney", "uniqueidentifier", "xml", "sql_variant", "image", "timestamp", "rowversion",
+			"hierarchyid", "geography", "geometry":
+			return false
+		}
+	}
+	return true
+}
+
+// mssqlNumber 将数值文本转换为批量复制可以写入的类型：整数为 int64，浮点数为 float64，定点数保留文本
+func mssqlNumber(kind, val string) (interface{}, error) {
+	switch kind {
+	case kindInteger:
+		i, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("不是有效的整数: %s", val)
+		}
+		return i, nil
+	case kindFloat:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		if err != nil {
+			return nil, fmt.Errorf("不是有效的数字: %s", val)
+		}
+		return f, nil
+	}
+	return val, nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlDialect MySQL，使用多行 INSERT 批量写入
+type mysqlDialect struct{ baseDialect }
+
+func (mysqlDialect) name() string { return "MySQL" }
+
+// open 中 serviceName 即数据库名
+func (mysqlDialect) open(ctx context.Context, p connParams) (*sql.DB, error) {
+	dbName := strings.TrimSpace(p.ServiceName)
+	if dbName == "" {
+		return nil, fmt.Errorf("MySQL 需要提供数据库名")
+	}
+
+	// 由驱动生成连接串，密码中的特殊字符不会破坏格式
+	cfg := mysql.NewConfig()
+	cfg.User = p.Username
+	cfg.Passwd = p.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(p.Host, p.Port)
+	cfg.DBName = dbName
+	cfg.Params = map[string]string{"charset": "utf8mb4"}
+	cfg.ParseTime = true
+	cfg.Loc = time.Local
+	return openDB(ctx, "mysql", cfg.FormatDSN())
+}
+
+func (mysqlDialect) describe(p connParams) string {
+	return fmt.Sprintf("MySQL: %s:%s/%s", p.Host, p.Port, p.ServiceName)
+}
+
+func (mysqlDialect) quote(ident string) string {
+	return "`" + strings.ReplaceAll(ident, "`", "``") + "`"
+}
+
+// resolveTable 支持 数据库.表名，未指定数据库时使用连接的当前数据库
+func (d mysqlDialect) resolveTable(ctx context.Context, db *sql.DB, name string) (tableRef, error) {
+	schema, table, err := splitTableName(d, name)
+	if err != nil {
+		return tableRef{}, err
+	}
+	query := `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`
+	args := []interface{}{schema, table}
+	if schema == "" {
+		query = `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`
+		args = args[1:]
+	}
+	var t tableRef
+	err = db.QueryRowContext(ctx, query, args...).Scan(&t.Schema, &t.Name)
+	if err == sql.ErrNoRows {
+		return t, fmt.Errorf("表 [%s] 不存在或无权限访问", name)
+	}
+	if err != nil {
+		return t, fmt.Errorf("查询表 [%s] 失败: %v", name, err)
+	}
+	return t, nil
+}
+
+func (mysqlDialect) columnNamesQuery() string {
+	return `
+SELECT COLUMN_NAME
+FROM INFORMATION_SCHEMA.COLUMNS
+WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+}
+
+// TEXT 类型的上限按字节计算
+func (mysqlDialect) columnsQuery(context.Context, *sql.DB) (string, error) {
+	return `SELECT COLUMN_NAME, DATA_TYPE, COALESCE(CHARACTER_MAXIMUM_LENGTH, 0), IS_NULLABLE,
+				         COALESCE(NUMERIC_PRECISION, 0), COALESCE(NUMERIC_SCALE, -1), COALESCE(CHARACTER_MAXIMUM_LENGTH, 0), COALESCE(CHARACTER_OCTET_LENGTH, 0),
+				         IF(DATA_TYPE LIKE '%text', 'B', 'C'),
+				         COLUMN_DEFAULT, EXTRA, COLUMN_TYPE, COLUMN_COMMENT
+				  FROM information_schema.COLUMNS
+				  WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+				  ORDER BY ORDINAL_POSITION`, nil
+}
+
+func (mysqlDialect) scanColumn(rows *sql.Rows) (TableColumnInfo, error) {
+	var r columnRow
+	if err := rows.Scan(append(r.fields(), &r.flag1, &r.c.ColumnType, &r.comment)...); err != nil {
+		return TableColumnInfo{}, err
+	}
+	c := r.info()
+	extra := strings.ToUpper(r.flag1)
+	c.Identity = strings.Contains(extra, "AUTO_INCREMENT")
+	c.Virtual = strings.Contains(extra, "VIRTUAL GENERATED") || strings.Contains(extra, "STORED GENERATED")
+	return c, nil
+}
+
+func (mysqlDialect) keyColumnsQuery() string {
+	return `SELECT COLUMN_NAME, IF(INDEX_NAME = 'PRIMARY', 'P', 'U')
+				  FROM information_schema.STATISTICS
+				  WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND NON_UNIQUE = 0`
+}
+
+func (mysqlDialect) primaryKeyQuery() string {
+	return `SELECT COLUMN_NAME
+				  FROM information_schema.KEY_COLUMN_USAGE
+				  WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'
+				  ORDER BY ORDINAL_POSITION`
+}
+
+func (mysqlDialect) uniqueKeysQuery() string {
+	return `SELECT INDEX_NAME, COLUMN_NAME
+				  FROM information_schema.STATISTICS
+				  WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND NON_UNIQUE = 0
+				  ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+}
+
+// schemasQuery 排除系统库
+func (mysqlDialect) schemasQuery() string {
+	return `SELECT SCHEMA_NAME FROM information_schema.SCHEMATA
+				  WHERE SCHEMA_NAME NOT IN ('information_schema', 'mysql', 'performance_schema', 'sys')
+				  ORDER BY SCHEMA_NAME`
+}
+
+func (mysqlDialect) currentSchemaQuery() string { return `SELECT DATABASE()` }
+
+// tablesQuery 中 InnoDB 的 TABLE_ROWS 为估算值，视图为空
+func (mysqlDialect) tablesQuery() string {
+	return `SELECT TABLE_NAME, IF(TABLE_TYPE = 'VIEW', 'VIEW', 'TABLE'), TABLE_ROWS,
+				         IF(TABLE_TYPE = 'VIEW', NULL, TABLE_COMMENT), NULL
+				  FROM information_schema.TABLES
+				  WHERE TABLE_SCHEMA = ?
+				  ORDER BY TABLE_NAME`
+}
+
+// placeholder 日期列按统一格式转换，开启截断时使用 SUBSTRING 按字符截取
+func (mysqlDialect) placeholder(c boundColumn, n int, enableTruncation bool) string {
+	kind := c.kind()
+	if kind == kindDate {
+		return "STR_TO_DATE(?, '%Y-%m-%d %H:%i:%s')"
+	} else if enableTruncation && kind == kindString && c.CharLength > 0 {
+		return fmt.Sprintf("SUBSTRING(?, 1, %d)", c.CharLength)
+	}
+	return "?"
+}
+
+// errorColumn 从 MySQL 错误中取出列名：1406 值过长、1048 不能为 NULL、1264 超出范围、
+// 1292/1366 值格式不正确，消息中均为 column 'xxx'
+func (mysqlDialect) errorColumn(err error) string {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return ""
+	}
+	switch myErr.Number {
+	case 1406, 1048, 1264, 1292, 1366:
+		if m := quotedColumn.FindStringSubmatch(myErr.Message); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// ON DUPLICATE KEY UPDATE 不能指定键，主键或任一唯一索引重复都会转为更新
+func (mysqlDialect) upsertAnyUniqueKey() bool { return true }
+
+// upsertSQL 在 INSERT 后追加 ON DUPLICATE KEY UPDATE，该子句同样追加在多行 INSERT 之后
+func (mysqlDialect) upsertSQL(table, insertSQL string, names, placeholders []string, keyIdx []int) (string, string) {
+	isKey := make(map[int]bool)
+	for _, i := range keyIdx {
+		isKey[i] = true
+	}
+	var sets []string
+	for i, name := range names {
+		if !isKey[i] {
+			sets = append(sets, fmt.Sprintf("%s = VALUES(%s)", name, name))
+		}
+	}
+	if len(sets) == 0 {
+		// 没有非键列时保持原值，仅避免重复键报错，导入结果中计为未修改
+		name := names[keyIdx[0]]
+		sets = append(sets, fmt.Sprintf("%s = %s", name, name))
+	}
+	suffix := " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	return insertSQL + suffix, suffix
+}
+
+// writeBatch 构建多行 INSERT，每一行的占位符与单行语句一致，参数按列顺序追加
+func (mysqlDialect) writeBatch(ctx context.Context, tx *sql.Tx, b *batch) error {
+	count := b.count()
+	if count == 1 {
+		_, err := tx.ExecContext(ctx, b.writeSQL, b.row(0)...)
+		return err
+	}
+
+	var valuePlaceholders []string
+	var allArgs []interface{}
+	rowPlaceholders := "(" + strings.Join(b.placeholders, ",") + ")"
+	for k := 0; k < count; k++ {
+		allArgs = append(allArgs, b.row(k)...)
+		valuePlaceholders = append(valuePlaceholders, rowPlaceholders)
+	}
+	bulkInsertSQL := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", b.table, b.columnList, strings.Join(valuePlaceholders, ",")) + b.upsertSuffix
+	_, err := tx.ExecContext(ctx, bulkInsertSQL, allArgs...)
+	return err
+}
+
+func (mysqlDialect) integerRange(c TableColumnInfo) (*big.Int, *big.Int, bool) {
+	return integerRange(c, false)
+}
+
+func (mysqlDialect) integerFraction() (string, string) {
+	return "warning", "小数部分将被舍入为整数"
+}
+
+func (mysqlDialect) inferType(s *columnStats) string {
+	switch s.kind() {
+	case kindInteger:
+		switch {
+		case s.intDigits <= 9:
+			return "INT"
+		case s.intDigits <= 18:
+			return "BIGINT"
+		}
+		return fmt.Sprintf("DECIMAL(%d,0)", min(s.intDigits+2, 65))
+	case kindDecimal:
+		return fmt.Sprintf("DECIMAL(%d,%d)", min(s.precision(), 65), min(s.scale, 30))
+	case kindDate:
+		if s.hasTime {
+			return "DATETIME"
+		}
+		return "DATE"
+	}
+	switch {
+	case s.maxChars > 16000:
+		return "MEDIUMTEXT"
+	case s.maxChars > 2000:
+		return "TEXT"
+	}
+	return fmt.Sprintf("VARCHAR(%d)", roundUpLength(s.maxChars))
+}
+
+func (mysqlDialect) identifierLimit() (int, bool) { return 64, false }
+
+func (mysqlDialect) createTableSuffix() string { return " DEFAULT CHARSET=utf8mb4" }
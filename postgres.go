@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// postgresDialect PostgreSQL，使用 COPY 批量写入
+type postgresDialect struct{ baseDialect }
+
+func (postgresDialect) name() string { return "PostgreSQL" }
+
+// open 中 serviceName 为数据库名，connectionType 为 sslmode
+func (postgresDialect) open(ctx context.Context, p connParams) (*sql.DB, error) {
+	dbName := strings.TrimSpace(p.ServiceName)
+	if dbName == "" {
+		return nil, fmt.Errorf("PostgreSQL 需要提供数据库名")
+	}
+	dsn, err := pgDSN(p.Host, p.Port, p.Username, p.Password, dbName, p.ConnectionType)
+	if err != nil {
+		return nil, err
+	}
+	return openDB(ctx, "postgres", dsn)
+}
+
+func (postgresDialect) describe(p connParams) string {
+	return fmt.Sprintf("PostgreSQL: %s:%s/%s (sslmode=%s)", p.Host, p.Port, p.ServiceName, p.ConnectionType)
+}
+
+// pgTableOID 按 (schema, 表名) 参数得到表的 oid，名称按原样匹配
+const pgTableOID = `to_regclass(format('%I.%I', $1::text, $2::text))`
+
+// resolveTable 中未指定 schema 的表名按 search_path 解析
+func (d postgresDialect) resolveTable(ctx context.Context, db *sql.DB, name string) (tableRef, error) {
+	schema, table, err := splitTableName(d, name)
+	if err != nil {
+		return tableRef{}, err
+	}
+	schema, table, err = pgResolveTable(ctx, db, qualifiedName(d, tableRef{Schema: schema, Name: table}))
+	if err != nil {
+		return tableRef{}, err
+	}
+	return tableRef{Schema: schema, Name: table}, nil
+}
+
+func (postgresDialect) columnNamesQuery() string {
+	return `
+SELECT attname
+FROM pg_attribute
+WHERE attrelid = ` + pgTableOID + ` AND attnum > 0 AND NOT attisdropped
+ORDER BY attnum`
+}
+
+func (postgresDialect) columnsQuery(context.Context, *sql.DB) (string, error) {
+	return pgColumnsQuery, nil
+}
+
+func (postgresDialect) scanColumn(rows *sql.Rows) (TableColumnInfo, error) {
+	var r columnRow
+	if err := rows.Scan(append(r.fields(), &r.flag1, &r.flag2, &r.c.ColumnType, &r.comment)...); err != nil {
+		return TableColumnInfo{}, err
+	}
+	c := r.info()
+	// serial 列表现为 nextval() 默认值
+	c.Identity = r.flag1 == "YES" || strings.HasPrefix(c.Default, "nextval(")
+	c.Virtual = r.flag2 == "ALWAYS"
+	return c, nil
+}
+
+func (postgresDialect) keyColumnsQuery() string {
+	return `SELECT a.attname, CASE WHEN i.indisprimary THEN 'P' ELSE 'U' END
+				  FROM pg_index i
+				  JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+				  WHERE i.indrelid = ` + pgTableOID + ` AND i.indisunique`
+}
+
+func (postgresDialect) primaryKeyQuery() string {
+	return `SELECT a.attname
+				  FROM pg_index i
+				  JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+				  WHERE i.indrelid = ` + pgTableOID + ` AND i.indisprimary
+				  ORDER BY array_position(i.indkey::int2[], a.attnum)`
+}
+
+func (postgresDialect) uniqueKeysQuery() string {
+	return `SELECT ic.relname, a.attname
+				  FROM pg_index i
+				  JOIN pg_class ic ON ic.oid = i.indexrelid
+				  JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+				  WHERE i.indrelid = ` + pgTableOID + ` AND i.indisunique
+				  ORDER BY ic.relname, array_position(i.indkey::int2[], a.attnum)`
+}
+
+// schemasQuery 排除系统 schema 以及没有 USAGE 权限的 schema
+func (postgresDialect) schemasQuery() string {
+	return `SELECT nspname FROM pg_namespace
+				  WHERE nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema' AND has_schema_privilege(oid, 'USAGE')
+				  ORDER BY nspname`
+}
+
+func (postgresDialect) currentSchemaQuery() string { return `SELECT current_schema()` }
+
+// tablesQuery 中行数取自 reltuples，从未 ANALYZE 的表为 -1，按未知处理
+func (postgresDialect) tablesQuery() string {
+	return `SELECT c.relname, CASE WHEN c.relkind IN ('v', 'm') THEN 'VIEW' ELSE 'TABLE' END,
+				         CASE WHEN c.reltuples >= 0 THEN c.reltuples::bigint END,
+				         obj_description(c.oid, 'pg_class'), NULL::text
+				  FROM pg_class c
+				  JOIN pg_namespace n ON n.oid = c.relnamespace
+				  WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'v', 'm', 'f') AND has_table_privilege(c.oid, 'SELECT')
+				  ORDER BY c.relname`
+}
+
+// placeholder 日期与字符串由服务端按列类型转换，截断在 convert 中完成
+func (postgresDialect) placeholder(c boundColumn, n int, enableTruncation bool) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// convert COPY 无法在写入时调用函数，截断在这里完成
+func (postgresDialect) convert(c boundColumn, val string, enableTruncation bool) (interface{}, error) {
+	return convertValue(c, val, enableTruncation)
+}
+
+func (postgresDialect) upsertSQL(table, insertSQL string, names, placeholders []string, keyIdx []int) (string, string) {
+	return onConflictSQL(insertSQL, names, keyIdx), ""
+}
+
+// writeBatch 追加时使用 COPY；ON CONFLICT 不允许同一语句中出现重复的键，upsert 逐行执行
+func (postgresDialect) writeBatch(ctx context.Context, tx *sql.Tx, b *batch) error {
+	if b.upsert {
+		return execRows(ctx, tx, b)
+	}
+	// COPY 需要表实际所在的 schema 与表名
+	schema, table, err := pgResolveTable(ctx, tx, b.table)
+	if err != nil {
+		return err
+	}
+	return pgCopy(ctx, tx, schema, table, b.columns, b.buffers)
+}
+
+// PostgreSQL 中语句出错会使整个事务失效，每一行都需要单独的保存点
+func (postgresDialect) rowSavepoints() bool { return true }
+
+// errorColumn 使用服务器返回的列名字段，NOT NULL 等约束错误会填写，值过长(22001)不会
+func (postgresDialect) errorColumn(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Column
+	}
+	return ""
+}
+
+func (postgresDialect) integerRange(c TableColumnInfo) (*big.Int, *big.Int, bool) {
+	return integerRange(c, false)
+}
+
+// PostgreSQL 不接受带小数的文本写入整数列
+func (postgresDialect) integerFraction() (string, string) {
+	return "error", "整数列不能包含小数"
+}
+
+func (postgresDialect) inferType(s *columnStats) string {
+	switch s.kind() {
+	case kindInteger:
+		switch {
+		case s.intDigits <= 9:
+			return "INTEGER"
+		case s.intDigits <= 18:
+			return "BIGINT"
+		}
+		return fmt.Sprintf("NUMERIC(%d)", s.intDigits+2)
+	case kindDecimal:
+		return fmt.Sprintf("NUMERIC(%d,%d)", s.precision(), s.scale)
+	case kindBoolean:
+		return "BOOLEAN"
+	case kindDate:
+		if s.hasTime {
+			return "TIMESTAMP"
+		}
+		return "DATE"
+	}
+	if s.maxChars > 2000 {
+		return "TEXT"
+	}
+	return fmt.Sprintf("VARCHAR(%d)", roundUpLength(s.maxChars))
+}
+
+// PostgreSQL 将未加引号的标识符转为小写
+func (postgresDialect) foldIdentifier(id string) string { return strings.ToLower(id) }
+
+func (postgresDialect) identifierLimit() (int, bool) { return 63, true }
+
+// PostgreSQL 独有的保留字(如 END、OFFSET、USING)，USER、ORDER 等已在 reservedWords 中
+var pgReservedWords = map[string]bool{
+	"ANALYSE": true, "ANALYZE": true, "ARRAY": true, "ASYMMETRIC": true, "AUTHORIZATION": true, "BINARY": true,
+	"BOTH": true, "CAST": true, "COLLATE": true, "COLLATION": true, "CONCURRENTLY": true, "CONSTRAINT": true,
+	"CURRENT_CATALOG": true, "CURRENT_DATE": true, "CURRENT_ROLE": true, "CURRENT_SCHEMA": true,
+	"CURRENT_TIME": true, "CURRENT_TIMESTAMP": true, "CURRENT_USER": true, "DEFERRABLE": true, "DO": true,
+	"END": true, "EXCEPT": true, "FALSE": true, "FETCH": true, "FREEZE": true, "FULL": true, "ILIKE": true,
+	"INITIALLY": true, "ISNULL": true, "LATERAL": true, "LEADING": true, "LOCALTIME": true,
+	"LOCALTIMESTAMP": true, "NATURAL": true, "NOTNULL": true, "OFFSET": true, "ONLY": true, "OVERLAPS": true,
+	"PLACING": true, "RETURNING": true, "SESSION_USER": true, "SIMILAR": true, "SOME": true, "SYMMETRIC": true,
+	"SYSTEM_USER": true, "TABLESAMPLE": true, "TRAILING": true, "TRUE": true, "USING": true, "VARIADIC": true,
+	"VERBOSE": true, "WINDOW": true,
+}
+
+func (postgresDialect) isReserved(id string) bool {
+	id = strings.ToUpper(id)
+	return reservedWords[id] || pgReservedWords[id]
+}
+
+// PostgreSQL 支持的 sslmode
+var pgSSLModes = map[string]bool{
+	"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+}
+
+// pgDSN 生成 PostgreSQL 连接串，sslMode 为空时使用 prefer
+func pgDSN(host, port, username, password, dbName, sslMode string) (string, error) {
+	sslMode = strings.ToLower(strings.TrimSpace(sslMode))
+	if sslMode == "" {
+		sslMode = "prefer"
+	}
+	if !pgSSLModes[sslMode] {
+		return "", fmt.Errorf("不支持的 sslmode: %s", sslMode)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(username, password),
+		Host:     net.JoinHostPort(strings.Trim(host, "[]"), port), // IPv6 地址需要加方括号
+		Path:     "/" + dbName,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return u.String(), nil
+}
+
+// pgColumnsQuery 从 information_schema 与 pg_catalog 读取 (schema, 表名) 的列定义
+const pgColumnsQuery = `SELECT c.column_name, c.data_type, COALESCE(c.character_maximum_length, 0), c.is_nullable,
+		         CASE WHEN c.numeric_precision_radix = 10 THEN c.numeric_precision ELSE 0 END, COALESCE(c.numeric_scale, -1),
+		         COALESCE(c.character_maximum_length, 0), COALESCE(c.character_octet_length, 0), 'C',
+		         c.column_default, c.is_identity, c.is_generated, format_type(a.atttypid, a.atttypmod), col_description(cls.oid, a.attnum)
+		  FROM pg_class cls
+		  JOIN pg_namespace n ON n.oid = cls.relnamespace
+		  JOIN information_schema.columns c ON c.table_schema = n.nspname AND c.table_name = cls.relname
+		  JOIN pg_attribute a ON a.attrelid = cls.oid AND a.attname = c.column_name
+		  WHERE n.nspname = $1 AND cls.relname = $2
+		  ORDER BY c.ordinal_position`
+
+// pgResolveTable 按 search_path 解析表名，返回表实际所在的 schema 与表名
+func pgResolveTable(ctx context.Context, q sqlExecer, tableName string) (string, string, error) {
+	var schema, name string
+	err := q.QueryRowContext(ctx, `SELECT n.nspname, c.relname
+			  FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			  WHERE c.oid = to_regclass($1)`, tableName).Scan(&schema, &name)
+	if err == sql.ErrNoRows {
+		return "", "", fmt.Errorf("表 [%s] 不存在或无权限访问", tableName)
+	}
+	return schema, name, err
+}
+
+// pgCopy 使用 COPY FROM STDIN 将缓冲区中的数据写入 schema.table
+func pgCopy(ctx context.Context, tx *sql.Tx, schema, table string, columns []string, columnBuffers [][]interface{}) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyInSchema(schema, table, columns...))
+	if err != nil {
+		return err
+	}
+	count := len(columnBuffers[0])
+	row := make([]interface{}, len(columns))
+	for k := 0; k < count; k++ {
+		for i := range columns {
+			row[i] = columnBuffers[i][k]
+		}
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return err
+		}
+	}
+	// 不带参数的 Exec 结束 COPY，数据错误在此时返回
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return err
+	}
+	return stmt.Close()
+}
+
+// parseBool 识别常见的布尔值写法
+func parseBool(val string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "true", "t", "yes", "y", "1", "on", "是":
+		return true, nil
+	case "false", "f", "no", "n", "0", "off", "否":
+		return false, nil
+	}
+	return false, fmt.Errorf("不是有效的布尔值: %s", val)
+}
+
+// checkJSON 校验 json/jsonb 列的值
+func checkJSON(val string) error {
+	if !json.Valid([]byte(val)) {
+		return fmt.Errorf("不是有效的 JSON")
+	}
+	return nil
+}
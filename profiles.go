@@ -0,0 +1,442 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// configVersion 配置文件格式版本，格式变化时递增并在 migrateConfig 中补充旧版本的迁移
+const configVersion = 1
+
+// 从只保存一个连接的旧版配置迁移时使用的配置名
+const migratedProfileName = "默认"
+
+// configMu 保护配置文件的读-改-写
+var configMu sync.Mutex
+
+// storedProfile 配置文件中的一个命名连接配置
+type storedProfile struct {
+	Name string `json:"name"`
+	storedConfig
+}
+
+// configFile 配置文件 dbconfig.json 的内容
+type configFile struct {
+	Version        int             `json:"version"`
+	DefaultProfile string          `json:"defaultProfile"`
+	Profiles       []storedProfile `json:"profiles"`
+}
+
+// ProfileInfo 连接配置列表中的一项
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	DbType      string `json:"dbType"`
+	Description string `json:"description"` // 连接描述，如 MySQL: localhost:3306/test
+	Default     bool   `json:"default"`
+}
+
+// readConfigFile 读取配置文件，文件不存在时返回空配置，旧版本格式按 migrateConfig 迁移后写回
+func readConfigFile() (*configFile, error) {
+	path, err := getConfigPath()
+	if err != nil {
+		return nil, fmt.Errorf("无法获取配置路径: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &configFile{Version: configVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	var probe struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	cf, err := migrateConfig(data, probe.Version)
+	if err != nil || probe.Version == configVersion {
+		return cf, err
+	}
+	// 迁移后立即写回，原文件去掉明文密码后保留为备份
+	backup, err := withoutPassword(data)
+	if err == nil {
+		err = os.WriteFile(fmt.Sprintf("%s.v%d.bak", path, probe.Version), backup, 0o600)
+	}
+	if err != nil {
+		log.Printf("备份旧版配置文件失败: %v", err)
+	} else if err := writeConfigFile(cf); err != nil {
+		log.Printf("写入迁移后的配置文件失败: %v", err)
+	}
+	return cf, nil
+}
+
+// migrateConfig 将各版本的配置文件转换为当前格式
+func migrateConfig(data []byte, version int) (*configFile, error) {
+	switch {
+	case version > configVersion:
+		return nil, fmt.Errorf("配置文件版本 %d 高于程序支持的版本 %d，请升级程序", version, configVersion)
+	case version == 0:
+		// 版本 0 为只保存一个连接的旧格式，迁移为默认配置
+		var legacy storedConfig
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %v", err)
+		}
+		if legacy.Password != "" {
+			encryptLegacyPassword(&legacy)
+		}
+		log.Printf("已将旧版配置迁移为连接配置 [%s]", migratedProfileName)
+		return &configFile{
+			Version:        configVersion,
+			DefaultProfile: migratedProfileName,
+			Profiles:       []storedProfile{{Name: migratedProfileName, storedConfig: legacy}},
+		}, nil
+	}
+	var cf configFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	}
+	return &cf, nil
+}
+
+// encryptLegacyPassword 使用本机密钥加密旧版配置中的明文密码，加密失败时不保留密码
+func encryptLegacyPassword(stored *storedConfig) {
+	password := stored.Password
+	stored.Password = ""
+	stored.UsePassphrase = false
+	stored.PasswordSalt = ""
+	key, err := localKey(true)
+	if err == nil {
+		stored.EncryptedPassword, err = encryptSecret(key, password)
+	}
+	if err != nil {
+		log.Printf("加密旧版配置中的密码失败，密码未迁移，请重新输入: %v", err)
+		stored.EncryptedPassword = ""
+		return
+	}
+	stored.SavePassword = true
+}
+
+// withoutPassword 去掉旧版配置中的明文 password 字段，用于写入备份文件
+func withoutPassword(data []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "password")
+	return json.MarshalIndent(fields, "", "  ")
+}
+
+// writeConfigFile 按当前版本写入配置文件
+func writeConfigFile(cf *configFile) error {
+	path, err := getConfigPath()
+	if err != nil {
+		return fmt.Errorf("无法获取配置路径: %v", err)
+	}
+	cf.Version = configVersion
+	data, err := json.MarshalIndent(cf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化配置失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %v", err)
+	}
+	return nil
+}
+
+// find 按名称查找配置(不区分大小写)，不存在时返回 -1
+func (cf *configFile) find(name string) int {
+	for i, p := range cf.Profiles {
+		if strings.EqualFold(p.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookup 按名称查找配置，名称为空时返回默认配置
+func (cf *configFile) lookup(name string) (int, error) {
+	if strings.TrimSpace(name) == "" {
+		name = cf.DefaultProfile
+	}
+	i := cf.find(strings.TrimSpace(name))
+	if i < 0 {
+		return -1, fmt.Errorf("连接配置 [%s] 不存在", name)
+	}
+	return i, nil
+}
+
+// profileName 校验并清理配置名
+func profileName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("配置名称不能为空")
+	}
+	return name, nil
+}
+
+// updateConfigFile 在锁内读取配置文件，修改成功后写回
+func updateConfigFile(update func(cf *configFile) error) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	cf, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if err := update(cf); err != nil {
+		return err
+	}
+	return writeConfigFile(cf)
+}
+
+// profileConfig 返回配置内容并解密密码。使用主密码加密且本次运行尚未输入主密码时，
+// 返回的配置不含密码并标记 PasswordLocked，由前端提示输入后调用 UnlockProfile
+func (a *App) profileConfig(p storedProfile) DBConfig {
+	cfg := p.DBConfig
+	switch {
+	case p.EncryptedPassword != "":
+		if p.UsePassphrase && a.passphrase == "" {
+			cfg.PasswordLocked = true
+			break
+		}
+		password, err := decryptPassword(p.storedConfig, a.passphrase)
+		if err != nil {
+			log.Printf("解密连接配置 [%s] 的密码失败: %v", p.Name, err)
+			cfg.PasswordLocked = p.UsePassphrase
+			break
+		}
+		cfg.Password = password
+	case cfg.Password != "":
+		// 旧版本以明文保存的密码，重新保存时加密
+		log.Printf("连接配置 [%s] 中的密码为明文，重新保存后将加密存储", p.Name)
+		cfg.SavePassword = true
+	}
+	return cfg
+}
+
+// ListProfiles lists the saved connection profiles.
+func (a *App) ListProfiles() ([]ProfileInfo, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	cf, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	profiles := []ProfileInfo{}
+	for _, p := range cf.Profiles {
+		info := ProfileInfo{Name: p.Name, DbType: p.DbType, Default: strings.EqualFold(p.Name, cf.DefaultProfile)}
+		if d, err := lookupDialect(p.DbType); err == nil {
+			info.Description = d.describe(connParams{Host: p.Host, Port: p.Port, ConnectionType: p.ConnectionType,
+				ServiceName: profileServiceName(p.DBConfig), TnsConnection: p.TnsConnection})
+		}
+		profiles = append(profiles, info)
+	}
+	return profiles, nil
+}
+
+// profileServiceName 返回连接使用的数据库名：Oracle 为服务名/SID，其余数据库为 Database
+func profileServiceName(cfg DBConfig) string {
+	if strings.EqualFold(cfg.DbType, "oracle") || cfg.Database == "" {
+		return cfg.ServiceName
+	}
+	return cfg.Database
+}
+
+// LoadProfile returns the named profile (the default profile when name is empty).
+// An empty config is returned when nothing has been saved yet.
+func (a *App) LoadProfile(name string) (DBConfig, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	cf, err := readConfigFile()
+	if err != nil {
+		return DBConfig{}, err
+	}
+	if strings.TrimSpace(name) == "" && len(cf.Profiles) == 0 {
+		return DBConfig{}, nil
+	}
+	i, err := cf.lookup(name)
+	if err != nil {
+		return DBConfig{}, err
+	}
+	return a.profileConfig(cf.Profiles[i]), nil
+}
+
+// SaveProfile stores cfg under name. An existing profile with the same name is only
+// replaced when overwrite is true. The first saved profile becomes the default.
+func (a *App) SaveProfile(name string, cfg DBConfig, overwrite bool) error {
+	name, err := profileName(name)
+	if err != nil {
+		return err
+	}
+	p := storedProfile{Name: name, storedConfig: storedConfig{DBConfig: cfg}}
+	p.Password = ""
+	p.PasswordLocked = false
+	if cfg.SavePassword && cfg.Password != "" {
+		if err := a.encryptPassword(&p.storedConfig, cfg.Password); err != nil {
+			return fmt.Errorf("加密密码失败: %v", err)
+		}
+	}
+
+	return updateConfigFile(func(cf *configFile) error {
+		i := cf.find(name)
+		if i >= 0 && cfg.SavePassword && cfg.Password == "" {
+			// 未输入新密码(如取消了主密码解锁)时保留已保存的密文，避免密码被清空
+			old := cf.Profiles[i]
+			p.EncryptedPassword, p.PasswordSalt, p.UsePassphrase = old.EncryptedPassword, old.PasswordSalt, old.UsePassphrase
+		}
+		if i < 0 {
+			cf.Profiles = append(cf.Profiles, p)
+		} else if !overwrite {
+			return fmt.Errorf("连接配置 [%s] 已存在", cf.Profiles[i].Name)
+		} else {
+			p.Name = cf.Profiles[i].Name
+			cf.Profiles[i] = p
+		}
+		if cf.DefaultProfile == "" || cf.find(cf.DefaultProfile) < 0 {
+			cf.DefaultProfile = p.Name
+		}
+		log.Printf("连接配置 [%s] 已保存", p.Name)
+		return nil
+	})
+}
+
+// RenameProfile renames a profile; the new name must not belong to another profile.
+func (a *App) RenameProfile(oldName, newName string) error {
+	newName, err := profileName(newName)
+	if err != nil {
+		return err
+	}
+	return updateConfigFile(func(cf *configFile) error {
+		i := cf.find(strings.TrimSpace(oldName))
+		if i < 0 {
+			return fmt.Errorf("连接配置 [%s] 不存在", oldName)
+		}
+		if j := cf.find(newName); j >= 0 && j != i {
+			return fmt.Errorf("连接配置 [%s] 已存在", cf.Profiles[j].Name)
+		}
+		if strings.EqualFold(cf.DefaultProfile, cf.Profiles[i].Name) {
+			cf.DefaultProfile = newName
+		}
+		cf.Profiles[i].Name = newName
+		return nil
+	})
+}
+
+// DuplicateProfile copies a profile, including its encrypted password, under a new name.
+func (a *App) DuplicateProfile(name, newName string) error {
+	newName, err := profileName(newName)
+	if err != nil {
+		return err
+	}
+	return updateConfigFile(func(cf *configFile) error {
+		i := cf.find(strings.TrimSpace(name))
+		if i < 0 {
+			return fmt.Errorf("连接配置 [%s] 不存在", name)
+		}
+		if j := cf.find(newName); j >= 0 {
+			return fmt.Errorf("连接配置 [%s] 已存在", cf.Profiles[j].Name)
+		}
+		p := cf.Profiles[i]
+		p.Name = newName
+		cf.Profiles = append(cf.Profiles, p)
+		return nil
+	})
+}
+
+// DeleteProfile removes a profile. Deleting the default profile makes the first
+// remaining profile the default.
+func (a *App) DeleteProfile(name string) error {
+	return updateConfigFile(func(cf *configFile) error {
+		i := cf.find(strings.TrimSpace(name))
+		if i < 0 {
+			return fmt.Errorf("连接配置 [%s] 不存在", name)
+		}
+		cf.Profiles = append(cf.Profiles[:i], cf.Profiles[i+1:]...)
+		if cf.find(cf.DefaultProfile) < 0 {
+			cf.DefaultProfile = ""
+			if len(cf.Profiles) > 0 {
+				cf.DefaultProfile = cf.Profiles[0].Name
+			}
+		}
+		return nil
+	})
+}
+
+// SetDefaultProfile marks the profile loaded at startup.
+func (a *App) SetDefaultProfile(name string) error {
+	return updateConfigFile(func(cf *configFile) error {
+		i := cf.find(strings.TrimSpace(name))
+		if i < 0 {
+			return fmt.Errorf("连接配置 [%s] 不存在", name)
+		}
+		cf.DefaultProfile = cf.Profiles[i].Name
+		return nil
+	})
+}
+
+// SetMasterPassphrase remembers the master passphrase for this session; it is used to
+// encrypt the saved password and is never written to disk.
+func (a *App) SetMasterPassphrase(passphrase string) string {
+	if passphrase == "" {
+		return "错误: 主密码不能为空"
+	}
+	a.passphrase = passphrase
+	return "主密码已设置"
+}
+
+// UnlockProfile decrypts the saved password of a profile with the master passphrase
+// and returns the full config.
+func (a *App) UnlockProfile(name, passphrase string) (DBConfig, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	cf, err := readConfigFile()
+	if err != nil {
+		return DBConfig{}, err
+	}
+	i, err := cf.lookup(name)
+	if err != nil {
+		return DBConfig{}, err
+	}
+	p := cf.Profiles[i]
+	cfg := p.DBConfig
+	if p.EncryptedPassword == "" || !p.UsePassphrase {
+		return a.profileConfig(p), nil
+	}
+	password, err := decryptPassword(p.storedConfig, passphrase)
+	if err != nil {
+		return DBConfig{}, err
+	}
+	a.passphrase = passphrase
+	cfg.Password = password
+	return cfg, nil
+}
+
+// SaveConfig 保存为默认连接配置，尚无任何配置时新建名为“默认”的配置
+func (a *App) SaveConfig(cfg DBConfig) string {
+	name := migratedProfileName
+	if profiles, err := a.ListProfiles(); err == nil {
+		for _, p := range profiles {
+			if p.Default {
+				name = p.Name
+			}
+		}
+	}
+	if err := a.SaveProfile(name, cfg, true); err != nil {
+		return "错误: " + err.Error()
+	}
+	return "配置已保存"
+}
+
+// LoadConfig 读取默认连接配置
+func (a *App) LoadConfig() DBConfig {
+	cfg, err := a.LoadProfile("")
+	if err != nil {
+		log.Println("LoadConfig 读取失败:", err)
+	}
+	return cfg
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// useTempConfigDir 让配置文件与本机密钥文件写入临时目录
+func useTempConfigDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("AppData", dir)
+	path, err := getConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		version      int
+		wantPassword string // 迁移后解密得到的密码，为空表示不保存密码
+		errorHas     string
+	}{
+		{name: "旧版明文密码", data: `{"dbType":"oracle","host":"db","username":"scott","password":"tiger","savePassword":false}`,
+			wantPassword: "tiger"},
+		{name: "旧版没有密码", data: `{"dbType":"mysql","host":"db","username":"root"}`},
+		{name: "当前版本", version: configVersion,
+			data: `{"version":1,"defaultProfile":"生产","profiles":[{"name":"生产","dbType":"postgres","host":"pg"}]}`},
+		{name: "版本过高", version: configVersion + 1, data: `{"version":2}`, errorHas: "请升级程序"},
+		{name: "格式错误", data: `{"host":`, errorHas: "解析配置文件失败"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempConfigDir(t)
+			cf, err := migrateConfig([]byte(tt.data), tt.version)
+			if tt.errorHas != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+					t.Fatalf("错误 = %v，应包含 %q", err, tt.errorHas)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("迁移失败: %v", err)
+			}
+			if len(cf.Profiles) != 1 || !strings.EqualFold(cf.DefaultProfile, cf.Profiles[0].Name) {
+				t.Fatalf("迁移结果 = %+v，应只有一个默认配置", cf)
+			}
+			p := cf.Profiles[0]
+			if p.Password != "" {
+				t.Errorf("迁移后仍保留明文密码 %q", p.Password)
+			}
+			if out, _ := json.Marshal(cf); strings.Contains(string(out), `"tiger"`) {
+				t.Errorf("迁移后的配置中含有明文密码: %s", out)
+			}
+			if tt.wantPassword == "" {
+				if p.EncryptedPassword != "" {
+					t.Errorf("不应保存密码，实际为 %q", p.EncryptedPassword)
+				}
+				return
+			}
+			if !p.SavePassword || p.UsePassphrase {
+				t.Errorf("SavePassword = %v, UsePassphrase = %v，应使用本机密钥保存密码", p.SavePassword, p.UsePassphrase)
+			}
+			if got, err := decryptPassword(p.storedConfig, ""); err != nil || got != tt.wantPassword {
+				t.Errorf("解密密码 = %q, %v，应为 %q", got, err, tt.wantPassword)
+			}
+		})
+	}
+}
+
+// TestReadConfigFileMigratesLegacy 检查迁移后写回的配置文件与备份文件中都不含明文密码
+func TestReadConfigFileMigratesLegacy(t *testing.T) {
+	path := useTempConfigDir(t)
+	legacy := `{"dbType":"oracle","host":"db","port":"1521","username":"scott","password":"tiger","serviceName":"ORCL"}`
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewApp().LoadProfile("")
+	if err != nil {
+		t.Fatalf("读取配置失败: %v", err)
+	}
+	if cfg.Password != "tiger" || cfg.Host != "db" {
+		t.Errorf("迁移后的配置 = %+v", cfg)
+	}
+	for _, p := range []string{path, path + ".v0.bak"} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("读取 %s 失败: %v", filepath.Base(p), err)
+		}
+		if strings.Contains(string(data), "tiger") {
+			t.Errorf("%s 中含有明文密码:\n%s", filepath.Base(p), data)
+		}
+		if !strings.Contains(string(data), `"scott"`) {
+			t.Errorf("%s 中缺少原有设置:\n%s", filepath.Base(p), data)
+		}
+	}
+}
+
+// TestSaveProfileKeepsLockedPassword 未输入主密码时重新保存配置，已加密的密码应保留
+func TestSaveProfileKeepsLockedPassword(t *testing.T) {
+	tests := []struct {
+		name         string
+		savePassword bool
+		wantPassword string
+	}{
+		{name: "保留已保存的密码", savePassword: true, wantPassword: "tiger"},
+		{name: "取消保存密码", savePassword: false, wantPassword: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useTempConfigDir(t)
+			unlocked := &App{passphrase: "master"}
+			cfg := DBConfig{DbType: "oracle", Host: "db", Username: "scott", Password: "tiger", SavePassword: true, UsePassphrase: true}
+			if err := unlocked.SaveProfile("生产", cfg, false); err != nil {
+				t.Fatalf("保存失败: %v", err)
+			}
+
+			// 未输入主密码时读到的配置不含密码，修改主机后保存
+			locked := &App{}
+			cfg, err := locked.LoadProfile("生产")
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if !cfg.PasswordLocked || cfg.Password != "" {
+				t.Fatalf("未输入主密码时 PasswordLocked = %v, Password = %q", cfg.PasswordLocked, cfg.Password)
+			}
+			cfg.Host = "db2"
+			cfg.SavePassword = tt.savePassword
+			if err := locked.SaveProfile("生产", cfg, true); err != nil {
+				t.Fatalf("重新保存失败: %v", err)
+			}
+
+			cfg, err = unlocked.LoadProfile("生产")
+			if err != nil {
+				t.Fatalf("读取失败: %v", err)
+			}
+			if cfg.Host != "db2" || cfg.Password != tt.wantPassword || cfg.PasswordLocked {
+				t.Errorf("重新保存后 Host = %q, Password = %q, PasswordLocked = %v，应为 db2, %q, false",
+					cfg.Host, cfg.Password, cfg.PasswordLocked, tt.wantPassword)
+			}
+		})
+	}
+}
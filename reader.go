@@ -0,0 +1,553 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 数据源文件格式
+const (
+	sourceExcel = "excel"
+	sourceCSV   = "csv"
+)
+
+// ImportOptions 前端传入的文件读取/导入选项
+type ImportOptions struct {
+	Delimiter   string          `json:"delimiter"`   // CSV 分隔符，留空时自动识别
+	SheetName   string          `json:"sheetName"`   // 工作表名，留空时使用第一个工作表
+	SheetTables []SheetTable    `json:"sheetTables"` // 多工作表导入时的工作表与表对应关系
+	Mappings    []ColumnMapping `json:"mappings"`    // 列映射，留空时按列名自动匹配
+	LoadMode    string          `json:"loadMode"`    // 导入模式: append(默认) / upsert / truncate / delete / replace
+	KeyColumns  []string        `json:"keyColumns"`  // upsert 使用的键列，留空时使用主键
+	DeleteWhere string          `json:"deleteWhere"` // delete 模式下删除旧数据的 WHERE 条件
+	Atomic      bool            `json:"atomic"`      // 单事务导入: 整个文件全部成功才提交
+	MaxErrors   int             `json:"maxErrors"`   // 允许跳过的出错行数: 0 遇到错误即停止，-1 不限制
+
+	// 以下行号均从 1 开始，与 Excel 中显示的行号一致；CSV 为文本中的物理行号(含空行)
+	HeaderRow    int `json:"headerRow"`    // 标题行，默认为 1
+	DataStartRow int `json:"dataStartRow"` // 数据起始行，默认为标题行的下一行
+	FooterRows   int `json:"footerRows"`   // 末尾需要跳过的行数(合计、备注等)
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// detectSourceType 根据扩展名判断文件格式，无法判断时读取文件头进行嗅探
+func detectSourceType(filePath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv", ".tsv", ".txt":
+		return sourceCSV, nil
+	case ".xlsx", ".xlsm", ".xltx", ".xltm":
+		return sourceExcel, nil
+	case ".xls":
+		// Excel 97-2003 的二进制格式 excelize 无法读取
+		return "", fmt.Errorf("不支持 .xls 格式(Excel 97-2003)，请在 Excel 中另存为 .xlsx 后再导入")
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer file.Close()
+
+	head := make([]byte, 8)
+	n, _ := io.ReadFull(file, head)
+	head = head[:n]
+	// xlsx 为 zip 包，加密的 xlsx 为 OLE 复合文档，均交给 excelize 处理
+	if bytes.HasPrefix(head, []byte("PK\x03\x04")) || bytes.HasPrefix(head, []byte{0xD0, 0xCF, 0x11, 0xE0}) {
+		return sourceExcel, nil
+	}
+	return sourceCSV, nil
+}
+
+// parseDelimiter 解析用户配置的分隔符，支持 "tab"/"\t" 写法
+func parseDelimiter(delimiter string) (rune, error) {
+	switch strings.ToLower(delimiter) {
+	case "":
+		return 0, nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("无效的分隔符: %q", delimiter)
+	}
+	return r, nil
+}
+
+// sniffDelimiter 统计首行中引号外各候选分隔符出现的次数，取最多者
+func sniffDelimiter(sample []byte) rune {
+	candidates := []rune{',', '\t', ';', '|'}
+	counts := make(map[rune]int)
+	inQuotes := false
+	for _, r := range string(sample) {
+		if r == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if !inQuotes && (r == '\n' || r == '\r') {
+			break
+		}
+		if !inQuotes {
+			counts[r]++
+		}
+	}
+	best := ','
+	for _, c := range candidates {
+		if counts[c] > counts[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+// newCsvReader 去掉 UTF-8 BOM 并按配置(或自动识别)的分隔符创建 csv.Reader
+func newCsvReader(r io.Reader, filePath, delimiter string) (*csv.Reader, error) {
+	comma, err := parseDelimiter(delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(r, 64*1024)
+	if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	if head, _ := br.Peek(2); bytes.Equal(head, []byte{0xFF, 0xFE}) || bytes.Equal(head, []byte{0xFE, 0xFF}) {
+		return nil, fmt.Errorf("暂不支持 UTF-16 编码的文件，请另存为 UTF-8")
+	}
+
+	if comma == 0 {
+		if strings.EqualFold(filepath.Ext(filePath), ".tsv") {
+			comma = '\t'
+		} else {
+			sample, _ := br.Peek(br.Size())
+			comma = sniffDelimiter(sample)
+		}
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = comma
+	// 允许各行字段数不一致，缺失的列按空值处理
+	reader.FieldsPerRecord = -1
+	return reader, nil
+}
+
+// readFileHeaders 按配置的标题行读取文件的列名
+func readFileHeaders(filePath string, opts ImportOptions) ([]string, error) {
+	reader, err := openTableReader(filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return reader.Headers(), nil
+}
+
+// rowReader 按行流式读取数据源，内存占用与文件大小无关
+type rowReader interface {
+	// Next 读取下一行，读完或出错时返回 false
+	Next() bool
+	// Row 返回当前行，仅在下一次调用 Next 之前有效
+	Row() []string
+	// Line 返回当前行在源文件中的起始行号(从 1 开始)
+	Line() int
+	Err() error
+	// Progress 返回已读取的比例(0~1)，无法估算时返回 -1
+	Progress() float64
+	Close() error
+}
+
+// openRowReader 根据文件格式打开流式读取器
+func openRowReader(filePath string, opts ImportOptions) (rowReader, error) {
+	sourceType, err := detectSourceType(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if sourceType == sourceCSV {
+		return openCsvRowReader(filePath, opts.Delimiter)
+	}
+	return openExcelRowReader(filePath, opts.SheetName)
+}
+
+// countingReader 统计已读取的字节数，用于计算 CSV 读取进度
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type csvRowReader struct {
+	file    *os.File
+	counter *countingReader
+	size    int64
+	reader  *csv.Reader
+	row     []string
+	err     error
+}
+
+func openCsvRowReader(filePath, delimiter string) (*csvRowReader, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("打开文件失败: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("读取文件信息失败: %v", err)
+	}
+
+	counter := &countingReader{r: file}
+	reader, err := newCsvReader(counter, filePath, delimiter)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	reader.ReuseRecord = true
+
+	return &csvRowReader{file: file, counter: counter, size: info.Size(), reader: reader}, nil
+}
+
+func (c *csvRowReader) Next() bool {
+	if c.err != nil {
+		return false
+	}
+	row, err := c.reader.Read()
+	if err != nil {
+		if err != io.EOF {
+			c.err = fmt.Errorf("解析CSV文件失败: %v", err)
+		}
+		c.row = nil
+		return false
+	}
+	c.row = row
+	return true
+}
+
+func (c *csvRowReader) Row() []string { return c.row }
+
+// Line 返回当前记录的第一个字段所在的物理行号，字段内含换行或文件中有空行时与记录序号不同
+func (c *csvRowReader) Line() int {
+	if c.row == nil {
+		return 0
+	}
+	line, _ := c.reader.FieldPos(0)
+	return line
+}
+
+func (c *csvRowReader) Err() error { return c.err }
+
+func (c *csvRowReader) Progress() float64 {
+	if c.size <= 0 {
+		return -1
+	}
+	// 读取器带缓冲，已读字节会略超前于已解析的行
+	return math.Min(float64(c.counter.n)/float64(c.size), 1)
+}
+
+func (c *csvRowReader) Close() error { return c.file.Close() }
+
+type excelRowReader struct {
+	file      *excelize.File
+	rows      *excelize.Rows
+	row       []string
+	read      int
+	totalRows int
+	err       error
+}
+
+func openExcelRowReader(filePath, sheet string) (*excelRowReader, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开Excel文件: %v", err)
+	}
+
+	sheetName, err := resolveSheetName(f, sheet)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("读取工作表失败: %v", err)
+	}
+
+	return &excelRowReader{file: f, rows: rows, totalRows: sheetDimensionRows(f, sheetName)}, nil
+}
+
+// resolveSheetName 校验指定的工作表是否存在，未指定时返回第一个工作表
+func resolveSheetName(f *excelize.File, sheet string) (string, error) {
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("Excel文件不包含任何工作表")
+	}
+	sheet = strings.TrimSpace(sheet)
+	if sheet == "" {
+		return sheets[0], nil
+	}
+	for _, name := range sheets {
+		if strings.EqualFold(name, sheet) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("工作表 [%s] 不存在", sheet)
+}
+
+// SheetInfo 工作表名称及行数
+type SheetInfo struct {
+	Name     string `json:"name"`
+	RowCount int    `json:"rowCount"`
+}
+
+// listSheets 列出文件中的工作表及行数，CSV 文件视为只有一个以文件名命名的工作表
+func listSheets(filePath string, opts ImportOptions) ([]SheetInfo, error) {
+	sourceType, err := detectSourceType(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if sourceType == sourceCSV {
+		reader, err := openCsvRowReader(filePath, opts.Delimiter)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		count := 0
+		for reader.Next() {
+			count++
+		}
+		if err := reader.Err(); err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+		return []SheetInfo{{Name: name, RowCount: count}}, nil
+	}
+
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开Excel文件: %v", err)
+	}
+	defer f.Close()
+
+	var sheets []SheetInfo
+	for _, name := range f.GetSheetList() {
+		count := sheetDimensionRows(f, name)
+		if count <= 1 {
+			// dimension 缺失或不可靠时逐行统计
+			count, err = countSheetRows(f, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		sheets = append(sheets, SheetInfo{Name: name, RowCount: count})
+	}
+	return sheets, nil
+}
+
+// countSheetRows 使用流式迭代器统计工作表的行数
+func countSheetRows(f *excelize.File, sheetName string) (int, error) {
+	rows, err := f.Rows(sheetName)
+	if err != nil {
+		return 0, fmt.Errorf("读取工作表 [%s] 失败: %v", sheetName, err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Error(); err != nil {
+		return 0, fmt.Errorf("读取工作表 [%s] 失败: %v", sheetName, err)
+	}
+	return count, nil
+}
+
+// sheetDimensionRows 根据工作表的 dimension 估算总行数，无记录时返回 0
+func sheetDimensionRows(f *excelize.File, sheetName string) int {
+	dimension, err := f.GetSheetDimension(sheetName)
+	if err != nil || dimension == "" {
+		return 0
+	}
+	parts := strings.Split(dimension, ":")
+	_, lastRow, err := excelize.CellNameToCoordinates(parts[len(parts)-1])
+	if err != nil {
+		return 0
+	}
+	return lastRow
+}
+
+func (e *excelRowReader) Next() bool {
+	if e.err != nil || !e.rows.Next() {
+		e.row = nil
+		if e.err == nil && e.rows.Error() != nil {
+			e.err = fmt.Errorf("读取工作表失败: %v", e.rows.Error())
+		}
+		return false
+	}
+	row, err := e.rows.Columns()
+	if err != nil {
+		e.err = fmt.Errorf("读取工作表失败: %v", err)
+		e.row = nil
+		return false
+	}
+	e.row = row
+	e.read++
+	return true
+}
+
+func (e *excelRowReader) Row() []string { return e.row }
+
+// Line 返回当前行的行号，流式迭代器逐行返回(包括空行)，已读取的行数即为行号
+func (e *excelRowReader) Line() int { return e.read }
+
+func (e *excelRowReader) Err() error { return e.err }
+
+func (e *excelRowReader) Progress() float64 {
+	if e.totalRows <= 1 {
+		return -1
+	}
+	return math.Min(float64(e.read)/float64(e.totalRows), 1)
+}
+
+func (e *excelRowReader) Close() error {
+	e.rows.Close()
+	return e.file.Close()
+}
+
+// tableReader 在 rowReader 之上定位标题行与数据区域，跳过空行和末尾的汇总行，
+// 并记录每一行在源文件中的行号
+type tableReader struct {
+	src     rowReader
+	headers []string
+	footer  int
+	first   *sourceRow  // 定位数据起始行时读到的第一行数据
+	pending []sourceRow // 为跳过末尾行而预读的数据
+	cur     sourceRow
+}
+
+type sourceRow struct {
+	line   int
+	values []string
+}
+
+// openTableReader 打开文件并读取到数据起始行之前
+func openTableReader(filePath string, opts ImportOptions) (*tableReader, error) {
+	headerRow := opts.HeaderRow
+	if headerRow <= 0 {
+		headerRow = 1
+	}
+	dataStartRow := opts.DataStartRow
+	if dataStartRow <= 0 {
+		dataStartRow = headerRow + 1
+	}
+	if dataStartRow <= headerRow {
+		return nil, fmt.Errorf("数据起始行(%d)必须大于标题行(%d)", dataStartRow, headerRow)
+	}
+	if opts.FooterRows < 0 {
+		return nil, fmt.Errorf("末尾跳过行数不能为负数")
+	}
+
+	src, err := openRowReader(filePath, opts)
+	if err != nil {
+		return nil, err
+	}
+	t := &tableReader{src: src, footer: opts.FooterRows}
+
+	// 按源文件中的行号定位：CSV 会跳过空行，一条记录也可能跨多行，
+	// 因此取起始行号不小于标题行的第一条记录作为标题，读到数据起始行时停止
+	found := false
+	for src.Next() {
+		line := src.Line()
+		if line >= dataStartRow {
+			// 读取器会复用行缓冲，需要拷贝保存
+			t.first = &sourceRow{line: line, values: append([]string(nil), src.Row()...)}
+			break
+		}
+		if !found && line >= headerRow {
+			t.headers = append([]string(nil), src.Row()...)
+			found = true
+		}
+	}
+	if err := src.Err(); err != nil {
+		src.Close()
+		return nil, err
+	}
+	if !found && t.first == nil {
+		src.Close()
+		return nil, fmt.Errorf("文件不足 %d 行，找不到标题行", headerRow)
+	}
+	if len(t.headers) == 0 {
+		src.Close()
+		return nil, fmt.Errorf("第%d行(标题行)内容为空", headerRow)
+	}
+	return t, nil
+}
+
+// Headers 返回标题行
+func (t *tableReader) Headers() []string { return t.headers }
+
+// Next 读取下一行非空数据，末尾 footer 行不会被返回
+func (t *tableReader) Next() bool {
+	for {
+		var row sourceRow
+		if t.first != nil {
+			row, t.first = *t.first, nil
+		} else if t.src.Next() {
+			row = sourceRow{line: t.src.Line(), values: t.src.Row()}
+		} else {
+			t.cur = sourceRow{}
+			return false
+		}
+		if isBlankRow(row.values) {
+			continue
+		}
+		if t.footer == 0 {
+			t.cur = row
+			return true
+		}
+
+		// 预读 footer 行，缓冲区满后才输出最早的一行
+		t.pending = append(t.pending, sourceRow{line: row.line, values: append([]string(nil), row.values...)})
+		if len(t.pending) > t.footer {
+			t.cur = t.pending[0]
+			t.pending = t.pending[1:]
+			return true
+		}
+	}
+}
+
+// Row 返回当前数据行
+func (t *tableReader) Row() []string { return t.cur.values }
+
+// Line 返回当前数据行在源文件中的行号
+func (t *tableReader) Line() int { return t.cur.line }
+
+func (t *tableReader) Err() error { return t.src.Err() }
+
+func (t *tableReader) Progress() float64 { return t.src.Progress() }
+
+func (t *tableReader) Close() error { return t.src.Close() }
+
+func isBlankRow(values []string) bool {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
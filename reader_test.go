@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectSourceType(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name     string
+		content  string
+		want     string
+		errorHas string
+	}{
+		{name: "data.CSV", want: sourceCSV},
+		{name: "data.xlsx", want: sourceExcel},
+		{name: "data.xls", errorHas: "请在 Excel 中另存为 .xlsx"},
+		{name: "export.dat", content: "PK\x03\x04", want: sourceExcel},
+		{name: "export.dat", content: "a,b\n1,2\n", want: sourceCSV},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		got, err := detectSourceType(path)
+		if tt.errorHas != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.errorHas) {
+				t.Errorf("%s: 错误 = %v，应包含 %q", tt.name, err, tt.errorHas)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%s = %q, %v，应为 %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		sample string
+		want   rune
+	}{
+		{sample: "a,b,c\n1,2,3", want: ','},
+		{sample: "a\tb\tc\n", want: '\t'},
+		{sample: "a;b;c\r\n1;2;3", want: ';'},
+		{sample: "a|b|c", want: '|'},
+		{sample: `"x,y,z";b;c` + "\n", want: ';'},
+		{sample: "\"多行\n标题,,,\"|b|c\n", want: '|'},
+		{sample: "a;b,c\n1;2;3;4;5", want: ','},
+		{sample: "单列标题\n", want: ','},
+		{sample: "", want: ','},
+	}
+	for _, tt := range tests {
+		if got := sniffDelimiter([]byte(tt.sample)); got != tt.want {
+			t.Errorf("sniffDelimiter(%q) = %q，应为 %q", tt.sample, got, tt.want)
+		}
+	}
+}
+
+func TestCsvRowReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	content := "\ufeff编号;名称\n1;\"第一行\n第二行\"\n2;\"含;分号\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := openRowReader(path, ImportOptions{})
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	defer reader.Close()
+	var rows []string
+	for reader.Next() {
+		rows = append(rows, strings.Join(reader.Row(), "|"))
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	want := []string{"编号|名称", "1|第一行\n第二行", "2|含;分号"}
+	if strings.Join(rows, "/") != strings.Join(want, "/") {
+		t.Errorf("读取结果 = %q，应为 %q", rows, want)
+	}
+	if p := reader.Progress(); p != 1 {
+		t.Errorf("读完后进度 = %v，应为 1", p)
+	}
+}
+
+// TestTableReaderLines CSV 中的空行与跨行字段不影响行号，行号与文本编辑器中显示的一致
+func TestTableReaderLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	content := "导出说明\n\n编号,名称\n1,\"第一行\n第二行\"\n\n2,b\n合计,2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := openTableReader(path, ImportOptions{HeaderRow: 3, FooterRows: 1})
+	if err != nil {
+		t.Fatalf("打开文件失败: %v", err)
+	}
+	defer reader.Close()
+	if got := strings.Join(reader.Headers(), "|"); got != "编号|名称" {
+		t.Errorf("标题行 = %q，应为 %q", got, "编号|名称")
+	}
+	var rows []string
+	for reader.Next() {
+		rows = append(rows, fmt.Sprintf("%d:%s", reader.Line(), reader.Row()[0]))
+	}
+	if err := reader.Err(); err != nil {
+		t.Fatalf("读取失败: %v", err)
+	}
+	if want := []string{"4:1", "7:2"}; strings.Join(rows, " ") != strings.Join(want, " ") {
+		t.Errorf("数据行 = %v，应为 %v", rows, want)
+	}
+}
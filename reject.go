@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"github.com/xuri/excelize/v2"
+)
+
+// rejectWriter 将导入失败的行连同原始数据写入临时 CSV 文件，首次写入时才创建文件
+type rejectWriter struct {
+	headers []string
+	path    string
+	file    *os.File
+	w       *csv.Writer
+	count   int
+}
+
+// quotedColumn 匹配 MySQL 与 SQL Server 错误消息中的 column 'xxx'
+var quotedColumn = regexp.MustCompile(`(?i)\bcolumn '([^']+)'`)
+
+// failedColumn 确定写入失败的行中出错的列：优先使用数据库错误中给出的列名，
+// 否则按导入前校验的规则逐列检查该行，仍无法确定时返回空
+func failedColumn(d dialect, err error, cols []boundColumn, row []string, enableTruncation bool) string {
+	if name := d.errorColumn(err); name != "" {
+		for _, c := range cols {
+			if strings.EqualFold(c.ColumnName, name) {
+				return c.ColumnName
+			}
+		}
+		return name
+	}
+	for _, c := range cols {
+		if level, _ := checkValue(d, c.TableColumnInfo, c.value(row), enableTruncation); level == "error" {
+			return c.ColumnName
+		}
+	}
+	return ""
+}
+
+// checkMaxErrors 校验允许跳过的出错行数，-1 表示不限制，更小的负数视为输入错误而不是不限制
+func checkMaxErrors(n int) error {
+	if n < -1 {
+		return fmt.Errorf("允许跳过的出错行数只能是 -1(不限制)、0 或正数，当前为 %d", n)
+	}
+	return nil
+}
+
+func newRejectWriter(headers []string) *rejectWriter {
+	return &rejectWriter{headers: append([]string(nil), headers...)}
+}
+
+// add 记录一行失败数据：文件中的行号、出错的列(未知时为空)、错误信息和原始行
+func (r *rejectWriter) add(line int, column, message string, row []string) error {
+	if r.w == nil {
+		f, err := os.CreateTemp("", "csv2o_rejects_*.csv")
+		if err != nil {
+			return fmt.Errorf("创建错误记录文件失败: %v", err)
+		}
+		// 写入 UTF-8 BOM，便于 Excel 直接打开中文内容
+		f.WriteString("\ufeff")
+		r.file, r.path = f, f.Name()
+		r.w = csv.NewWriter(f)
+		if err := r.w.Write(append([]string{"行号", "出错列", "错误信息"}, r.headers...)); err != nil {
+			return fmt.Errorf("写入错误记录文件失败: %v", err)
+		}
+	}
+	record := append([]string{fmt.Sprint(line), column, message}, row...)
+	if err := r.w.Write(record); err != nil {
+		return fmt.Errorf("写入错误记录文件失败: %v", err)
+	}
+	r.count++
+	return nil
+}
+
+func (r *rejectWriter) Close() error {
+	if r.w == nil {
+		return nil
+	}
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		r.file.Close()
+		return err
+	}
+	return r.file.Close()
+}
+
+// SaveRejectFile lets the user save a reject file produced by ImportExcel as CSV or xlsx.
+func (a *App) SaveRejectFile(rejectPath string) string {
+	if a.ctx == nil {
+		return "错误: 窗口尚未就绪"
+	}
+	// 只允许另存本程序生成的错误记录文件
+	if filepath.Dir(rejectPath) != filepath.Clean(os.TempDir()) || !strings.HasPrefix(filepath.Base(rejectPath), "csv2o_rejects_") {
+		return "错误: 无效的错误记录文件"
+	}
+
+	dest, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "保存错误记录",
+		DefaultFilename: "rejects.xlsx",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Excel文件 (*.xlsx)", Pattern: "*.xlsx"},
+			{DisplayName: "CSV文件 (*.csv)", Pattern: "*.csv"},
+		},
+	})
+	if err != nil {
+		return fmt.Sprintf("错误: 打开保存对话框失败: %v", err)
+	}
+	if dest == "" {
+		return ""
+	}
+
+	if strings.EqualFold(filepath.Ext(dest), ".xlsx") {
+		err = convertRejectsToXlsx(rejectPath, dest)
+	} else {
+		err = copyFile(rejectPath, dest)
+	}
+	if err != nil {
+		return fmt.Sprintf("错误: 保存错误记录失败: %v", err)
+	}
+	return "错误记录已保存到: " + dest
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// convertRejectsToXlsx 将错误记录 CSV 转成 xlsx，所有单元格按文本写入以保留原始内容
+func convertRejectsToXlsx(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+
+	f := excelize.NewFile()
+	defer f.Close()
+	sheet := f.GetSheetName(0)
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+	for rowNo := 1; ; rowNo++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if rowNo == 1 && len(record) > 0 {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+		cells := make([]interface{}, len(record))
+		for i, v := range record {
+			cells[i] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, rowNo)
+		if err := sw.SetRow(cell, cells); err != nil {
+			return err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	return f.SaveAs(dest)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	mssql "github.com/microsoft/go-mssqldb"
+)
+
+func TestRejectWriter(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+
+	// 没有出错行时不创建文件
+	empty := newRejectWriter([]string{"id"})
+	if err := empty.Close(); err != nil || empty.path != "" {
+		t.Fatalf("没有出错行时 path = %q, err = %v", empty.path, err)
+	}
+
+	headers := []string{"编号", "名称"}
+	w := newRejectWriter(headers)
+	headers[0] = "已修改" // 调用方复用标题行切片不应影响错误记录
+	if err := w.add(3, "QTY", "不是有效的数字", []string{"1", "a,b"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.add(7, "", "ORA-00001: 违反唯一约束条件", []string{"2", "多\n行"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.count != 2 || !strings.HasPrefix(filepath.Base(w.path), "csv2o_rejects_") {
+		t.Errorf("count = %d, path = %s", w.count, w.path)
+	}
+
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "\ufeff") {
+		t.Error("错误记录文件缺少 UTF-8 BOM")
+	}
+	records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(data), "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("解析错误记录文件失败: %v", err)
+	}
+	want := [][]string{
+		{"行号", "出错列", "错误信息", "编号", "名称"},
+		{"3", "QTY", "不是有效的数字", "1", "a,b"},
+		{"7", "", "ORA-00001: 违反唯一约束条件", "2", "多\n行"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("错误记录 = %q，应为 %q", records, want)
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("第 %d 行 = %q，应为 %q", i+1, records[i], want[i])
+		}
+	}
+}
+
+func TestFailedColumn(t *testing.T) {
+	cols := []boundColumn{
+		{TableColumnInfo: TableColumnInfo{ColumnName: "NAME", DataType: "VARCHAR", CharUsed: "C", CharLength: 3, Nullable: true}, Action: mapColumn, SourceIdx: 0},
+		{TableColumnInfo: TableColumnInfo{ColumnName: "QTY", DataType: "INT", Nullable: true}, Action: mapColumn, SourceIdx: 1},
+	}
+	tests := []struct {
+		name   string
+		d      dialect
+		err    error
+		row    []string
+		column string
+	}{
+		{name: "Oracle 值过长", d: oracleDialect{}, row: []string{"ab", "1"},
+			err: errors.New(`ORA-12899: value too large for column "SCOTT"."T"."NAME" (actual: 12, maximum: 10)`), column: "NAME"},
+		{name: "Oracle 不能为空", d: oracleDialect{}, row: []string{"ab", ""},
+			err: errors.New(`ORA-01400: cannot insert NULL into ("SCOTT"."T"."QTY")`), column: "QTY"},
+		{name: "MySQL 值过长", d: mysqlDialect{}, row: []string{"ab", "1"},
+			err: &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'name' at row 1"}, column: "NAME"},
+		{name: "MySQL 主键重复", d: mysqlDialect{}, row: []string{"ab", "1"},
+			err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}, column: ""},
+		{name: "PostgreSQL 列名字段", d: postgresDialect{}, row: []string{"ab", "1"},
+			err: &pq.Error{Code: "23502", Column: "qty"}, column: "QTY"},
+		{name: "SQL Server 值过长", d: mssqlDialect{}, row: []string{"ab", "1"},
+			err: mssql.Error{Number: 2628, Message: "String or binary data would be truncated in table 'db.dbo.T', column 'NAME'. Truncated value: 'ab'."}, column: "NAME"},
+		{name: "SQLite 不能为空", d: sqliteDialect{}, row: []string{"ab", ""},
+			err: errors.New("NOT NULL constraint failed: items.qty"), column: "QTY"},
+		{name: "错误中没有列名时按校验规则检查", d: postgresDialect{}, row: []string{"abcd", "1"},
+			err: &pq.Error{Code: "22001", Message: "value too long for type character varying(3)"}, column: "NAME"},
+	}
+	for _, tt := range tests {
+		if got := failedColumn(tt.d, tt.err, cols, tt.row, false); got != tt.column {
+			t.Errorf("%s: 出错列 = %q，应为 %q", tt.name, got, tt.column)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// 列的数据类别，用于类型转换与校验
+const (
+	kindString  = "string"
+	kindInteger = "integer"
+	kindDecimal = "decimal"
+	kindFloat   = "float"
+	kindDate    = "date"
+	kindBoolean = "boolean"
+	kindJSON    = "json"
+	kindOther   = "other"
+)
+
+// columnRow 各数据库列定义查询结果中共有的字段，以及由各数据库解释的自增与生成列标记
+type columnRow struct {
+	c            TableColumnInfo
+	nullable     string
+	defaultValue sql.NullString
+	comment      sql.NullString
+	flag1, flag2 string
+}
+
+// fields 返回前十列 (列名, 类型, 长度, 可空, 精度, 小数位数, 字符长度, 字节长度, 长度语义, 默认值) 的扫描目标
+func (r *columnRow) fields() []interface{} {
+	return []interface{}{&r.c.ColumnName, &r.c.DataType, &r.c.DataLength, &r.nullable,
+		&r.c.Precision, &r.c.Scale, &r.c.CharLength, &r.c.OctetLength, &r.c.CharUsed, &r.defaultValue}
+}
+
+// info 填充可空、默认值与注释后返回列信息
+func (r *columnRow) info() TableColumnInfo {
+	c := r.c
+	c.Nullable = r.nullable == "Y" || r.nullable == "YES"
+	c.Default = strings.TrimSpace(r.defaultValue.String)
+	c.HasDefault = r.defaultValue.Valid && c.Default != "" && !strings.EqualFold(c.Default, "NULL")
+	c.Comment = r.comment.String
+	return c
+}
+
+// resolveTable 将用户输入的表名(可写成 schema.表名)解析为实际存在的表。
+// 导入与校验只使用解析结果经 qualifiedName 加引号后的名称，不会直接拼接用户输入
+func resolveTable(ctx context.Context, db *sql.DB, d dialect, tableName string) (tableRef, error) {
+	tableName = strings.TrimSpace(tableName)
+	if tableName == "" {
+		return tableRef{}, fmt.Errorf("表名不能为空")
+	}
+	table, err := d.resolveTable(ctx, db, tableName)
+	if err != nil {
+		return table, err
+	}
+	// 同义词等解析得到的名称来自数据库，同样不允许包含引号
+	if strings.ContainsAny(table.Schema, identifierQuotes) || strings.ContainsAny(table.Name, identifierQuotes) {
+		return tableRef{}, fmt.Errorf("表 [%s] 的名称中含有引号字符，不支持导入", table)
+	}
+	return table, nil
+}
+
+// queryTableColumns 查询目标表的列信息(按列顺序)
+func queryTableColumns(ctx context.Context, db *sql.DB, d dialect, table tableRef) ([]TableColumnInfo, error) {
+	query, err := d.columnsQuery(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.QueryContext(ctx, query, table.args()...)
+	if err != nil {
+		return nil, fmt.Errorf("查询表结构失败: %v", err)
+	}
+	defer rows.Close()
+
+	var cols []TableColumnInfo
+	for rows.Next() {
+		c, err := d.scanColumn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("解析列信息失败: %v", err)
+		}
+		cols = append(cols, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("读取表结构时出错: %v", err)
+	}
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("表 [%s] 不存在、无权限访问或不包含任何列", table)
+	}
+
+	primary, unique, err := queryKeyColumns(ctx, db, d, table)
+	if err != nil {
+		return nil, err
+	}
+	for i := range cols {
+		name := strings.ToUpper(cols[i].ColumnName)
+		cols[i].PrimaryKey = primary[name]
+		cols[i].Unique = unique[name]
+	}
+	return cols, nil
+}
+
+// queryKeyColumns 查询属于主键以及属于唯一约束/唯一索引的列(列名大写)
+func queryKeyColumns(ctx context.Context, db *sql.DB, d dialect, table tableRef) (map[string]bool, map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, d.keyColumnsQuery(), table.args()...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询主键与唯一索引失败: %v", err)
+	}
+	defer rows.Close()
+
+	primary := make(map[string]bool)
+	unique := make(map[string]bool)
+	for rows.Next() {
+		var col, keyType string
+		if err := rows.Scan(&col, &keyType); err != nil {
+			return nil, nil, fmt.Errorf("查询主键与唯一索引失败: %v", err)
+		}
+		col = strings.ToUpper(col)
+		if keyType == "P" {
+			primary[col] = true
+		}
+		// 主键同样保证唯一
+		unique[col] = true
+	}
+	return primary, unique, rows.Err()
+}
+
+// kind 按数据库类型名判断列的数据类别
+func (c TableColumnInfo) kind() string {
+	t := strings.ToUpper(c.DataType)
+	switch {
+	case strings.EqualFold(c.ColumnType, "rowversion"):
+		// SQL Server 的 timestamp 列报告的类型名为 timestamp，实际是二进制版本号而不是日期
+		return kindOther
+	case t == "DATE" || strings.HasPrefix(t, "DATETIME") || t == "SMALLDATETIME" || strings.HasPrefix(t, "TIMESTAMP"):
+		return kindDate
+	case t == "NUMBER":
+		if c.Scale == 0 {
+			return kindInteger
+		}
+		return kindDecimal
+	case t == "DECIMAL" || t == "NUMERIC" || t == "MONEY" || t == "SMALLMONEY":
+		return kindDecimal
+	case t == "TINYINT" || t == "SMALLINT" || t == "MEDIUMINT" || t == "INT" || t == "INTEGER" || t == "BIGINT":
+		return kindInteger
+	case t == "FLOAT" || t == "DOUBLE" || t == "REAL" || t == "BINARY_FLOAT" || t == "BINARY_DOUBLE" || t == "DOUBLE PRECISION":
+		return kindFloat
+	case t == "CHAR" || t == "VARCHAR" || t == "VARCHAR2" || t == "NCHAR" || t == "NVARCHAR2" ||
+		t == "TINYTEXT" || t == "TEXT" || t == "MEDIUMTEXT" || t == "LONGTEXT" ||
+		t == "CHARACTER" || t == "CHARACTER VARYING" || t == "NVARCHAR" || t == "NTEXT":
+		return kindString
+	case t == "BOOLEAN" || (t == "BIT" && c.Precision <= 1):
+		return kindBoolean
+	case t == "JSON" || t == "JSONB":
+		return kindJSON
+	}
+	return kindOther
+}
+
+// lengthLimit 返回字符列的长度上限以及是否按字节计算，0 表示不限制
+func (c TableColumnInfo) lengthLimit() (int, bool) {
+	if c.CharUsed == "C" {
+		return c.CharLength, false
+	}
+	return c.OctetLength, true
+}
+
+// required 判断列是否必须由文件提供数据：不允许为空，且没有默认值、不是自增列或虚拟列
+func (c TableColumnInfo) required() bool {
+	return !c.Nullable && !c.HasDefault && !c.Identity && !c.Virtual
+}
+
+// GetColumnMetadata returns the full column definitions of tableName for display in the frontend.
+func (a *App) GetColumnMetadata(dbType, host, port, username, password, tableName, connectionType, serviceName, tnsConnection string) ([]TableColumnInfo, error) {
+	d, err := lookupDialect(dbType)
+	if err != nil {
+		return nil, err
+	}
+	db, err := connectDatabase(context.Background(), dbType, host, port, username, password, connectionType, serviceName, tnsConnection)
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	table, err := resolveTable(context.Background(), db, d, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return queryTableColumns(context.Background(), db, d, table)
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sqliteDialect SQLite 本地数据库文件，日期按 "2006-01-02 15:04:05" 文本保存
+type sqliteDialect struct{ baseDialect }
+
+func (sqliteDialect) name() string { return "SQLite" }
+
+// open 中 serviceName 为数据库文件路径，不需要主机、端口与用户名
+func (sqliteDialect) open(ctx context.Context, p connParams) (*sql.DB, error) {
+	return sqliteOpen(ctx, p.ServiceName)
+}
+
+func (sqliteDialect) describe(p connParams) string {
+	return fmt.Sprintf("SQLite: %s", p.ServiceName)
+}
+
+// resolveTable 中未指定 schema 的表名在 main 中查找，附加的数据库可写成 schema.table
+func (d sqliteDialect) resolveTable(ctx context.Context, db *sql.DB, name string) (tableRef, error) {
+	schema, table, err := splitTableName(d, name)
+	if err != nil {
+		return tableRef{}, err
+	}
+	if schema == "" {
+		schema = "main"
+	}
+	var t tableRef
+	err = db.QueryRowContext(ctx, `SELECT schema, name FROM pragma_table_list
+				  WHERE schema = ?1 AND name = ?2 COLLATE NOCASE AND type IN ('table', 'view')`, schema, table).Scan(&t.Schema, &t.Name)
+	if err == sql.ErrNoRows {
+		return t, fmt.Errorf("表 [%s] 不存在", name)
+	}
+	if err != nil {
+		return t, fmt.Errorf("查询表 [%s] 失败: %v", name, err)
+	}
+	return t, nil
+}
+
+func (sqliteDialect) columnNamesQuery() string {
+	return `
+SELECT name
+FROM pragma_table_info(?2, ?1)
+ORDER BY cid`
+}
+
+// schemasQuery 列出 main 与附加的数据库
+func (sqliteDialect) schemasQuery() string {
+	return `SELECT name FROM pragma_database_list WHERE name <> 'temp' ORDER BY seq`
+}
+
+func (sqliteDialect) currentSchemaQuery() string { return `SELECT 'main'` }
+
+// tablesQuery 排除 sqlite_ 开头的内部表，SQLite 不保存行数与注释
+func (sqliteDialect) tablesQuery() string {
+	return `SELECT name, upper(type), NULL, NULL, NULL
+				  FROM pragma_table_list
+				  WHERE schema = ?1 AND type IN ('table', 'view') AND name NOT LIKE 'sqlite\_%' ESCAPE '\'
+				  ORDER BY name`
+}
+
+// sqliteOpen 打开 SQLite 数据库文件，文件不存在时连同所在目录一起创建
+func sqliteOpen(ctx context.Context, path string) (*sql.DB, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("SQLite 需要提供数据库文件路径")
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建数据库文件所在目录失败: %v", err)
+		}
+	}
+	// 读取表结构与写入可能同时占用多个连接，遇到锁时等待而不是立即报 database is locked
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// columnsQuery 通过 PRAGMA table_info 读取列定义。
+// 单列 INTEGER PRIMARY KEY 是 rowid 的别名，未提供值时自动分配，按自增列处理
+func (sqliteDialect) columnsQuery(context.Context, *sql.DB) (string, error) {
+	return `SELECT name, type, "notnull", dflt_value,
+				         pk > 0 AND upper(type) = 'INTEGER' AND (SELECT COUNT(*) FROM pragma_table_info(?2, ?1) WHERE pk > 0) = 1
+				  FROM pragma_table_info(?2, ?1)
+				  ORDER BY cid`, nil
+}
+
+// scanColumn 中声明的长度与精度只记录在 ColumnType 中，SQLite 不限制，不参与截断与校验
+func (sqliteDialect) scanColumn(rows *sql.Rows) (TableColumnInfo, error) {
+	var c TableColumnInfo
+	var notNull int
+	var defaultValue sql.NullString
+	if err := rows.Scan(&c.ColumnName, &c.ColumnType, &notNull, &defaultValue, &c.Identity); err != nil {
+		return c, err
+	}
+	base, args, _ := strings.Cut(c.ColumnType, "(")
+	c.DataType = strings.ToUpper(strings.TrimSpace(base))
+	if n, err := strconv.Atoi(strings.TrimSpace(strings.Split(strings.TrimSuffix(args, ")"), ",")[0])); err == nil {
+		c.DataLength = n
+	}
+	c.Scale = -1
+	c.CharUsed = "C"
+	c.Nullable = notNull == 0
+	c.Default = strings.TrimSpace(defaultValue.String)
+	c.HasDefault = defaultValue.Valid && c.Default != "" && !strings.EqualFold(c.Default, "NULL")
+	return c, nil
+}
+
+// keyColumnsQuery 中 INTEGER PRIMARY KEY 没有对应的索引，主键列从 table_info 读取
+func (sqliteDialect) keyColumnsQuery() string {
+	return `SELECT name, 'P' FROM pragma_table_info(?2, ?1) WHERE pk > 0
+				  UNION
+				  SELECT ii.name, 'U'
+				  FROM pragma_index_list(?2, ?1) il
+				  JOIN pragma_index_info(il.name, ?1) ii
+				  WHERE il."unique" = 1`
+}
+
+func (sqliteDialect) primaryKeyQuery() string {
+	return `SELECT name FROM pragma_table_info(?2, ?1) WHERE pk > 0 ORDER BY pk`
+}
+
+// uniqueKeysQuery 中 INTEGER PRIMARY KEY 没有对应的索引，单独作为一组
+func (sqliteDialect) uniqueKeysQuery() string {
+	return `SELECT idx, col FROM (
+				    SELECT il.name AS idx, ii.name AS col, ii.seqno AS seq
+				    FROM pragma_index_list(?2, ?1) il
+				    JOIN pragma_index_info(il.name, ?1) ii
+				    WHERE il."unique" = 1
+				    UNION ALL
+				    SELECT '', name, pk FROM pragma_table_info(?2, ?1) WHERE pk > 0
+				  ) ORDER BY idx, seq`
+}
+
+func (sqliteDialect) upsertSQL(table, insertSQL string, names, placeholders []string, keyIdx []int) (string, string) {
+	return onConflictSQL(insertSQL, names, keyIdx), ""
+}
+
+// SQLite 不支持多列 IN 值列表
+func (sqliteDialect) rowValueIn() bool { return false }
+
+// sqliteNotNull 匹配 NOT NULL constraint failed: 表.列
+var sqliteNotNull = regexp.MustCompile(`NOT NULL constraint failed: (?:[^\s.]+\.)?(\S+)`)
+
+func (sqliteDialect) errorColumn(err error) string {
+	if m := sqliteNotNull.FindStringSubmatch(err.Error()); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// writeBatch 在事务内逐行执行预编译语句已足够快，也避免超出参数个数上限
+func (sqliteDialect) writeBatch(ctx context.Context, tx *sql.Tx, b *batch) error {
+	return execRows(ctx, tx, b)
+}
+
+// SQLite 没有 TRUNCATE，不带条件的 DELETE 会被优化为清空整表
+func (sqliteDialect) truncateSQL(table string) string { return "DELETE FROM " + table }
+
+// inferType 给出 SQLite 的列类型，SQLite 不限制长度与精度，只区分类型亲和性
+func (sqliteDialect) inferType(s *columnStats) string {
+	switch s.kind() {
+	case kindInteger:
+		if s.intDigits <= 18 {
+			return "INTEGER"
+		}
+		return "NUMERIC"
+	case kindDecimal:
+		return "NUMERIC"
+	case kindBoolean:
+		return "BOOLEAN"
+	case kindDate:
+		if s.hasTime {
+			return "DATETIME"
+		}
+		return "DATE"
+	}
+	return "TEXT"
+}
+
+func (sqliteDialect) identifierLimit() (int, bool) { return 64, false }
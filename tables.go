@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// 一次最多返回的表数量，Oracle 的 PUBLIC 同义词等可能有上万个，超出时需要输入关键字缩小范围
+const maxListedTables = 500
+
+// TableInfo 表浏览器中列出的表、视图或同义词
+type TableInfo struct {
+	Schema   string `json:"schema"`
+	Name     string `json:"name"`
+	Type     string `json:"type"` // TABLE、VIEW 或 SYNONYM
+	Rows     int64  `json:"rows"` // 估算行数，未知时为 -1
+	Comment  string `json:"comment"`
+	Target   string `json:"target"`   // 同义词指向的 schema.表名
+	FullName string `json:"fullName"` // 可直接填入目标表名的名称，非当前 schema 的表带 schema 前缀
+}
+
+// TableList 某个 schema 下符合条件的表
+type TableList struct {
+	Schema    string      `json:"schema"` // 实际查询的 schema，未指定时为当前 schema
+	Tables    []TableInfo `json:"tables"`
+	Truncated bool        `json:"truncated"` // 结果超过上限，只返回了前 maxListedTables 个
+}
+
+// identifierText 返回填入表名输入框的标识符，大小写或字符不能按未加引号的写法还原时加上引号
+func identifierText(d dialect, id string) string {
+	plain := id != "" && d.foldIdentifier(id) == id && !unicode.IsDigit([]rune(id)[0])
+	for _, r := range id {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '$' && r != '#' {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return id
+	}
+	return d.quote(id)
+}
+
+// matchTable 判断表名或注释中是否包含关键字(不区分大小写)
+func matchTable(t TableInfo, search string) bool {
+	if search == "" {
+		return true
+	}
+	return strings.Contains(strings.ToLower(t.Name), search) || strings.Contains(strings.ToLower(t.Comment), search)
+}
+
+// queryStrings 执行返回单列字符串的查询
+func queryStrings(db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v sql.NullString
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v.String)
+	}
+	return values, rows.Err()
+}
+
+// ListSchemas lists the schemas (Oracle owners, MySQL databases) visible to the connection.
+func (a *App) ListSchemas(dbType, host, port, username, password, connectionType, serviceName, tnsConnection string) ([]string, error) {
+	d, err := lookupDialect(dbType)
+	if err != nil {
+		return nil, err
+	}
+	db, err := connectDatabase(context.Background(), dbType, host, port, username, password, connectionType, serviceName, tnsConnection)
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	schemas, err := queryStrings(db, d.schemasQuery())
+	if err != nil {
+		return nil, fmt.Errorf("查询 schema 列表失败: %v", err)
+	}
+	return schemas, nil
+}
+
+// ListTables lists the tables, views and synonyms of schema (the current schema when empty)
+// whose name or comment contains search, with estimated row counts.
+func (a *App) ListTables(dbType, host, port, username, password, connectionType, serviceName, tnsConnection, schema, search string) (TableList, error) {
+	var result TableList
+	d, err := lookupDialect(dbType)
+	if err != nil {
+		return result, err
+	}
+	db, err := connectDatabase(context.Background(), dbType, host, port, username, password, connectionType, serviceName, tnsConnection)
+	if err != nil {
+		return result, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	var current string
+	if err := db.QueryRow(d.currentSchemaQuery()).Scan(&current); err != nil {
+		return result, fmt.Errorf("查询当前 schema 失败: %v", err)
+	}
+	result.Schema = strings.TrimSpace(schema)
+	if result.Schema == "" {
+		result.Schema = current
+	}
+
+	rows, err := db.Query(d.tablesQuery(), result.Schema)
+	if err != nil {
+		return result, fmt.Errorf("查询表列表失败: %v", err)
+	}
+	defer rows.Close()
+
+	search = strings.ToLower(strings.TrimSpace(search))
+	result.Tables = []TableInfo{}
+	for rows.Next() {
+		var t TableInfo
+		var count sql.NullInt64
+		var comment, target sql.NullString
+		if err := rows.Scan(&t.Name, &t.Type, &count, &comment, &target); err != nil {
+			return result, fmt.Errorf("读取表列表失败: %v", err)
+		}
+		t.Schema = result.Schema
+		t.Comment = comment.String
+		t.Target = target.String
+		t.Rows = -1
+		if count.Valid {
+			t.Rows = count.Int64
+		}
+		if !matchTable(t, search) {
+			continue
+		}
+		if len(result.Tables) == maxListedTables {
+			result.Truncated = true
+			break
+		}
+		t.FullName = identifierText(d, t.Name)
+		if t.Schema != current {
+			t.FullName = identifierText(d, t.Schema) + "." + t.FullName
+		}
+		result.Tables = append(result.Tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		return result, fmt.Errorf("读取表列表失败: %v", err)
+	}
+	return result, nil
+}
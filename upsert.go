@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// queryPrimaryKey 查询表的主键列(按键内顺序)
+func queryPrimaryKey(ctx context.Context, db *sql.DB, d dialect, table tableRef) ([]string, error) {
+	rows, err := db.QueryContext(ctx, d.primaryKeyQuery(), table.args()...)
+	if err != nil {
+		return nil, fmt.Errorf("查询主键失败: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var col string
+		if err := rows.Scan(&col); err != nil {
+			return nil, fmt.Errorf("查询主键失败: %v", err)
+		}
+		keys = append(keys, col)
+	}
+	return keys, rows.Err()
+}
+
+// queryUniqueKeys 查询表上所有主键/唯一索引的列组合
+func queryUniqueKeys(ctx context.Context, db *sql.DB, d dialect, table tableRef) ([][]string, error) {
+	rows, err := db.QueryContext(ctx, d.uniqueKeysQuery(), table.args()...)
+	if err != nil {
+		return nil, fmt.Errorf("查询唯一索引失败: %v", err)
+	}
+	defer rows.Close()
+
+	var keys [][]string
+	var lastIndex string
+	for rows.Next() {
+		var index, col string
+		if err := rows.Scan(&index, &col); err != nil {
+			return nil, fmt.Errorf("查询唯一索引失败: %v", err)
+		}
+		if index != lastIndex || len(keys) == 0 {
+			keys = append(keys, nil)
+			lastIndex = index
+		}
+		keys[len(keys)-1] = append(keys[len(keys)-1], col)
+	}
+	return keys, rows.Err()
+}
+
+// resolveKeyColumns 确定 upsert 使用的键列：优先使用用户指定的列，否则使用主键。
+// 返回键列在 insertCols 中的下标。tableCols 为表的全部列，用于判断未写入的自增列
+func resolveKeyColumns(ctx context.Context, db *sql.DB, d dialect, table tableRef, keyColumns []string, insertCols []boundColumn, tableCols []TableColumnInfo) ([]int, error) {
+	var keys []string
+	for _, k := range keyColumns {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		pk, err := queryPrimaryKey(ctx, db, d, table)
+		if err != nil {
+			return nil, err
+		}
+		if len(pk) == 0 {
+			return nil, fmt.Errorf("表 [%s] 没有主键，请指定 upsert 使用的键列", table)
+		}
+		keys = pk
+	}
+
+	var keyIdx []int
+	for _, k := range keys {
+		idx := -1
+		for i, c := range insertCols {
+			if strings.EqualFold(c.ColumnName, k) {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("键列 %s 未映射到文件中的列，无法进行 upsert", k)
+		}
+		keyIdx = append(keyIdx, idx)
+	}
+
+	// MySQL 的 ON DUPLICATE KEY UPDATE 与 PostgreSQL/SQLite 的 ON CONFLICT 只能依据主键或唯一索引判断重复
+	if d.uniqueKeysQuery() != "" {
+		uniqueKeys, err := queryUniqueKeys(ctx, db, d, table)
+		if err != nil {
+			return nil, err
+		}
+		if !containsKeySet(uniqueKeys, keys) {
+			return nil, fmt.Errorf("表 [%s] 上不存在由 (%s) 组成的主键或唯一索引，无法按该键 upsert", table, strings.Join(keys, ","))
+		}
+		// 其他唯一索引重复时 MySQL 也会更新已有的行，更新的可能是键值不同的行，新增与更新的统计也会出错
+		if d.upsertAnyUniqueKey() {
+			written := make(map[string]bool)
+			for _, c := range insertCols {
+				written[strings.ToUpper(c.ColumnName)] = true
+			}
+			generated := make(map[string]bool)
+			for _, c := range tableCols {
+				if c.Identity && !written[strings.ToUpper(c.ColumnName)] {
+					generated[strings.ToUpper(c.ColumnName)] = true
+				}
+			}
+			if index := uncoveredUniqueKey(uniqueKeys, keys, generated); index != nil {
+				return nil, fmt.Errorf("表 [%s] 上的唯一索引 (%s) 不在键列 (%s) 中，该索引重复时 %s 也会更新已有的行，请选择包含该索引列的键或改用其他导入模式",
+					table, strings.Join(index, ","), strings.Join(keys, ","), d.name())
+			}
+		}
+	}
+	return keyIdx, nil
+}
+
+// uncoveredUniqueKey 返回第一个列不全在 keys 中的唯一索引，全部覆盖时返回 nil。
+// 包含未写入的自增列(generated)的索引由数据库生成新值，不会与导入的行冲突，不需要覆盖
+func uncoveredUniqueKey(candidates [][]string, keys []string, generated map[string]bool) []string {
+	inKeys := make(map[string]bool)
+	for _, k := range keys {
+		inKeys[strings.ToUpper(k)] = true
+	}
+	for _, index := range candidates {
+		missing, exempt := false, false
+		for _, col := range index {
+			col = strings.ToUpper(col)
+			missing = missing || !inKeys[col]
+			exempt = exempt || generated[col]
+		}
+		if missing && !exempt {
+			return index
+		}
+	}
+	return nil
+}
+
+// containsKeySet 判断候选键中是否有与 keys 列集合相同的一组
+func containsKeySet(candidates [][]string, keys []string) bool {
+	normalize := func(cols []string) string {
+		upper := make([]string, len(cols))
+		for i, c := range cols {
+			upper[i] = strings.ToUpper(c)
+		}
+		sort.Strings(upper)
+		return strings.Join(upper, ",")
+	}
+	want := normalize(keys)
+	for _, c := range candidates {
+		if normalize(c) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeSQL 生成单行 MERGE 语句，from 为数据源 SELECT 之后的子句(如 Oracle 的 FROM dual)，
+// setPrefix 为 UPDATE SET 中目标列的前缀
+func mergeSQL(tableName string, names, placeholders []string, keyIdx []int, from, setPrefix string) string {
+	isKey := make(map[int]bool)
+	for _, i := range keyIdx {
+		isKey[i] = true
+	}
+
+	var selects, on, sets, values []string
+	for i, name := range names {
+		selects = append(selects, fmt.Sprintf("%s AS %s", placeholders[i], name))
+		values = append(values, "s."+name)
+		if isKey[i] {
+			on = append(on, fmt.Sprintf("d.%s = s.%s", name, name))
+		} else {
+			sets = append(sets, fmt.Sprintf("%s%s = s.%s", setPrefix, name, name))
+		}
+	}
+	stmt := fmt.Sprintf("MERGE INTO %s d USING (SELECT %s%s) s ON (%s)", tableName, strings.Join(selects, ", "), from, strings.Join(on, " AND "))
+	if len(sets) > 0 {
+		stmt += " WHEN MATCHED THEN UPDATE SET " + strings.Join(sets, ", ")
+	}
+	stmt += fmt.Sprintf(" WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s)", strings.Join(names, ","), strings.Join(values, ","))
+	return stmt
+}
+
+// onConflictSQL 在单行 INSERT 后追加 ON CONFLICT (键列) DO UPDATE
+func onConflictSQL(insertSQL string, names []string, keyIdx []int) string {
+	isKey := make(map[int]bool)
+	for _, i := range keyIdx {
+		isKey[i] = true
+	}
+
+	var keys, sets []string
+	for i, name := range names {
+		if isKey[i] {
+			keys = append(keys, name)
+		} else {
+			sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", name, name))
+		}
+	}
+	if len(sets) == 0 {
+		// 没有非键列时用键列自身赋值，已存在的行保持不变，导入结果中计为未修改
+		sets = append(sets, fmt.Sprintf("%s = EXCLUDED.%s", keys[0], keys[0]))
+	}
+	return fmt.Sprintf("%s ON CONFLICT (%s) DO UPDATE SET %s", insertSQL, strings.Join(keys, ","), strings.Join(sets, ", "))
+}
+
+// countNewKeys 在写入前统计缓冲区中将被新增(而非更新)的行数，结果是估算值：
+// 统计与写入之间其他会话提交的数据不会计入；MySQL 要求键列覆盖全部唯一索引(见 resolveKeyColumns)，
+// 否则其他唯一索引重复导致的更新会被计为新增。
+// 同一批中重复出现的键只有第一次算作新增；键中含空值的行总是新增。
+func countNewKeys(ctx context.Context, tx sqlExecer, d dialect, tableName string, insertCols []boundColumn, keyIdx []int, columnBuffers [][]interface{}, enableTruncation bool) (int, error) {
+	count := len(columnBuffers[0])
+	newRows := 0
+	seen := make(map[string]bool)
+	var distinct [][]interface{}
+	for k := 0; k < count; k++ {
+		key := make([]interface{}, len(keyIdx))
+		var sb strings.Builder
+		hasNull := false
+		for i, idx := range keyIdx {
+			v := columnBuffers[idx][k]
+			if v == nil {
+				hasNull = true
+			}
+			key[i] = v
+			fmt.Fprintf(&sb, "%v\x00", v)
+		}
+		if hasNull {
+			newRows++
+			continue
+		}
+		if seen[sb.String()] {
+			continue
+		}
+		seen[sb.String()] = true
+		distinct = append(distinct, key)
+	}
+	if len(distinct) == 0 {
+		return newRows, nil
+	}
+
+	// WHERE (k1,k2) IN ((..),(..))，键值使用与写入时相同的转换。
+	// SQL Server 与 SQLite 不支持多列 IN 值列表，改为 (k1 = .. AND k2 = ..) OR ...；
+	// SQL Server 单条语句最多 2100 个参数，按块查询
+	var names []string
+	for _, idx := range keyIdx {
+		names = append(names, d.quote(insertCols[idx].ColumnName))
+	}
+	rowCompare := !d.rowValueIn() && len(keyIdx) > 1
+	chunk := max(2000/len(keyIdx), 1)
+	existing := 0
+	for start := 0; start < len(distinct); start += chunk {
+		var tuples []string
+		var args []interface{}
+		for _, key := range distinct[start:min(start+chunk, len(distinct))] {
+			var ph []string
+			for i, idx := range keyIdx {
+				args = append(args, key[i])
+				p := d.placeholder(insertCols[idx], len(args), enableTruncation)
+				if rowCompare {
+					p = fmt.Sprintf("%s = %s", names[i], p)
+				}
+				ph = append(ph, p)
+			}
+			if rowCompare {
+				tuples = append(tuples, "("+strings.Join(ph, " AND ")+")")
+			} else {
+				tuples = append(tuples, "("+strings.Join(ph, ",")+")")
+			}
+		}
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE (%s) IN (%s)", tableName, strings.Join(names, ","), strings.Join(tuples, ","))
+		if rowCompare {
+			query = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", tableName, strings.Join(tuples, " OR "))
+		}
+
+		var n int
+		if err := tx.QueryRowContext(ctx, query, args...).Scan(&n); err != nil {
+			return 0, err
+		}
+		existing += n
+	}
+	// 用户指定的键列不唯一时，已存在的行数可能多于本批的键数
+	if existing > len(distinct) {
+		existing = len(distinct)
+	}
+	return newRows + len(distinct) - existing, nil
+}
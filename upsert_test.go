@@ -0,0 +1,101 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUpsertSQL(t *testing.T) {
+	cols := []string{"ID", "NAME", "QTY"}
+	tests := []struct {
+		name       string
+		dbType     string
+		cols       []string
+		keyIdx     []int
+		wantSQL    string
+		wantSuffix string
+	}{
+		{
+			name:   "Oracle MERGE",
+			dbType: "oracle",
+			cols:   cols,
+			keyIdx: []int{0},
+			wantSQL: "MERGE INTO T d USING (SELECT :1 AS ID, :2 AS NAME, :3 AS QTY FROM dual) s ON (d.ID = s.ID)" +
+				" WHEN MATCHED THEN UPDATE SET d.NAME = s.NAME, d.QTY = s.QTY" +
+				" WHEN NOT MATCHED THEN INSERT (ID,NAME,QTY) VALUES (s.ID,s.NAME,s.QTY)",
+		},
+		{
+			name:    "Oracle 只有键列时不更新",
+			dbType:  "oracle",
+			cols:    cols[:2],
+			keyIdx:  []int{0, 1},
+			wantSQL: "MERGE INTO T d USING (SELECT :1 AS ID, :2 AS NAME FROM dual) s ON (d.ID = s.ID AND d.NAME = s.NAME) WHEN NOT MATCHED THEN INSERT (ID,NAME) VALUES (s.ID,s.NAME)",
+		},
+		{
+			name:       "MySQL ON DUPLICATE KEY UPDATE",
+			dbType:     "mysql",
+			cols:       cols,
+			keyIdx:     []int{0},
+			wantSQL:    "INSERT INTO T VALUES (?) ON DUPLICATE KEY UPDATE NAME = VALUES(NAME), QTY = VALUES(QTY)",
+			wantSuffix: " ON DUPLICATE KEY UPDATE NAME = VALUES(NAME), QTY = VALUES(QTY)",
+		},
+		{
+			name:       "MySQL 只有键列时保持原值",
+			dbType:     "mysql",
+			cols:       cols[:1],
+			keyIdx:     []int{0},
+			wantSQL:    "INSERT INTO T VALUES (?) ON DUPLICATE KEY UPDATE ID = ID",
+			wantSuffix: " ON DUPLICATE KEY UPDATE ID = ID",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placeholders := []string{":1", ":2", ":3"}[:len(tt.cols)]
+			stmt, suffix := dialects[tt.dbType].upsertSQL("T", "INSERT INTO T VALUES (?)", tt.cols, placeholders, tt.keyIdx)
+			if stmt != tt.wantSQL || suffix != tt.wantSuffix {
+				t.Errorf("upsertSQL =\n%q, %q\n应为\n%q, %q", stmt, suffix, tt.wantSQL, tt.wantSuffix)
+			}
+		})
+	}
+}
+
+func TestContainsKeySet(t *testing.T) {
+	candidates := [][]string{{"ID"}, {"ORDER_NO", "LINE_NO"}}
+	tests := []struct {
+		keys []string
+		want bool
+	}{
+		{keys: []string{"id"}, want: true},
+		{keys: []string{"line_no", "Order_No"}, want: true},
+		{keys: []string{"ORDER_NO"}, want: false},
+		{keys: []string{"ID", "ORDER_NO"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := containsKeySet(candidates, tt.keys); got != tt.want {
+			t.Errorf("containsKeySet(%v) = %v，应为 %v", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestUncoveredUniqueKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		candidates [][]string
+		generated  map[string]bool
+		want       string
+	}{
+		{name: "只有主键", keys: []string{"id"}, candidates: [][]string{{"ID"}}},
+		{name: "键列包含全部唯一索引", keys: []string{"ORDER_NO", "LINE_NO"}, candidates: [][]string{{"ORDER_NO", "LINE_NO"}, {"ORDER_NO"}}},
+		{name: "其他唯一索引", keys: []string{"ID"}, candidates: [][]string{{"ID"}, {"CODE"}}, want: "CODE"},
+		{name: "未写入的自增主键", keys: []string{"CODE"}, candidates: [][]string{{"ID"}, {"CODE"}}, generated: map[string]bool{"ID": true}},
+		{name: "包含未写入自增列的组合索引", keys: []string{"CODE"}, candidates: [][]string{{"CODE"}, {"ID", "NAME"}}, generated: map[string]bool{"ID": true}},
+		{name: "自增列以外的列", keys: []string{"CODE"}, candidates: [][]string{{"ID"}, {"CODE"}, {"EMAIL"}}, generated: map[string]bool{"ID": true}, want: "EMAIL"},
+	}
+	for _, tt := range tests {
+		got := strings.Join(uncoveredUniqueKey(tt.candidates, tt.keys, tt.generated), ",")
+		if got != tt.want {
+			t.Errorf("%s: uncoveredUniqueKey = %q，应为 %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+)
+
+// 报告中最多列出的问题条数，超出后只计数
+const maxValidationIssues = 10000
+
+// ValidationIssue 校验发现的一个问题
+type ValidationIssue struct {
+	Sheet   string `json:"sheet"`
+	Table   string `json:"table"`
+	Row     int    `json:"row"` // 文件中的行号，0 表示与具体行无关
+	Column  string `json:"column"`
+	Value   string `json:"value"`
+	Level   string `json:"level"` // error: 导入时会失败；warning: 数据会被截断或舍入
+	Message string `json:"message"`
+}
+
+// ValidationReport 校验结果
+type ValidationReport struct {
+	TotalRows    int               `json:"totalRows"`
+	ErrorRows    int               `json:"errorRows"` // 至少有一个 error 的行数
+	ErrorCount   int               `json:"errorCount"`
+	WarningCount int               `json:"warningCount"`
+	Issues       []ValidationIssue `json:"issues"`
+	Truncated    bool              `json:"truncated"` // 问题过多，Issues 只包含前一部分
+}
+
+func (r *ValidationReport) add(issue ValidationIssue) {
+	if issue.Level == "error" {
+		r.ErrorCount++
+	} else {
+		r.WarningCount++
+	}
+	if len(r.Issues) >= maxValidationIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// ValidateImport walks the whole file against the target table definition without writing anything
+// and reports every value that would be rejected, truncated or rounded.
+func (a *App) ValidateImport(dbType, host, port, username, password, tableName, filePath, connectionType, serviceName, tnsConnection, truncateChars string, opts ImportOptions) (ValidationReport, error) {
+	var report ValidationReport
+
+	d, err := lookupDialect(dbType)
+	if err != nil {
+		return report, err
+	}
+	db, err := connectDatabase(context.Background(), dbType, host, port, username, password, connectionType, serviceName, tnsConnection)
+	if err != nil {
+		return report, fmt.Errorf("数据库连接失败: %v", err)
+	}
+	defer db.Close()
+
+	enableTruncation := truncateChars == "true"
+	if len(opts.SheetTables) == 0 {
+		err := a.validateSheet(&report, db, d, tableName, filePath, enableTruncation, opts)
+		return report, err
+	}
+	for _, st := range opts.SheetTables {
+		sheetOpts := opts
+		sheetOpts.SheetName = st.Sheet
+		sheetOpts.SheetTables = nil
+		sheetOpts.Mappings = st.Mappings
+		if err := a.validateSheet(&report, db, d, st.Table, filePath, enableTruncation, sheetOpts); err != nil {
+			return report, fmt.Errorf("工作表[%s] -> 表[%s]: %v", st.Sheet, st.Table, err)
+		}
+	}
+	return report, nil
+}
+
+func (a *App) validateSheet(report *ValidationReport, db *sql.DB, d dialect, tableName, filePath string, enableTruncation bool, opts ImportOptions) error {
+	reader, err := openTableReader(filePath, opts)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	ref, err := resolveTable(context.Background(), db, d, tableName)
+	if err != nil {
+		return err
+	}
+	dbCols, err := queryTableColumns(context.Background(), db, d, ref)
+	if err != nil {
+		return err
+	}
+	boundCols, err := bindColumns(dbCols, reader.Headers(), opts.Mappings)
+	if err != nil {
+		return err
+	}
+
+	// 交给默认值的列如果不允许为空又没有默认值，每一行都会写入失败
+	for _, c := range boundCols {
+		if c.Action == mapDefault && c.required() {
+			report.add(ValidationIssue{Sheet: opts.SheetName, Table: tableName, Column: c.ColumnName, Level: "error",
+				Message: "未映射到文件中的列，且不允许为空又没有默认值"})
+		}
+	}
+
+	rows := 0
+	for reader.Next() {
+		row := reader.Row()
+		line := reader.Line()
+		rows++
+		rowHasError := false
+		for _, c := range boundCols {
+			if c.Action == mapSkip || c.Action == mapDefault {
+				continue
+			}
+			val := c.value(row)
+			level, msg := checkValue(d, c.TableColumnInfo, val, enableTruncation)
+			if msg == "" {
+				continue
+			}
+			if level == "error" {
+				rowHasError = true
+			}
+			report.add(ValidationIssue{Sheet: opts.SheetName, Table: tableName, Row: line, Column: c.ColumnName, Value: val, Level: level, Message: msg})
+		}
+		if rowHasError {
+			report.ErrorRows++
+		}
+		if rows%1000 == 0 {
+			a.reportReadProgress(reader, rows, opts.SheetName)
+		}
+	}
+	report.TotalRows += rows
+	if err := reader.Err(); err != nil {
+		return fmt.Errorf("%v (已校验%d行)", err, rows)
+	}
+	return nil
+}
+
+// checkValue 按列定义检查一个值，返回问题级别与说明，没有问题时说明为空
+func checkValue(d dialect, c TableColumnInfo, val string, enableTruncation bool) (string, string) {
+	kind := c.kind()
+
+	// Oracle 将空串视为 NULL；其余数据库的字符列可以写入空串
+	if val == "" {
+		if !c.Nullable && (kind != kindString || d.emptyStringIsNull()) {
+			return "error", "不允许为空"
+		}
+		return "", ""
+	}
+
+	switch kind {
+	case kindDate:
+		if _, err := tryParseDate(val); err != nil {
+			return "error", "日期格式无法识别"
+		}
+	case kindString:
+		limit, inBytes := c.lengthLimit()
+		if limit <= 0 {
+			return "", ""
+		}
+		n, unit := utf8.RuneCountInString(val), "字符"
+		if inBytes {
+			n, unit = len(val), "字节"
+		}
+		if n > limit {
+			if enableTruncation {
+				return "warning", fmt.Sprintf("长度 %d %s超过上限 %d，将被截断", n, unit, limit)
+			}
+			return "error", fmt.Sprintf("长度 %d %s超过上限 %d", n, unit, limit)
+		}
+	case kindInteger, kindDecimal:
+		return checkNumber(d, c, val)
+	case kindFloat:
+		if _, ok := new(big.Float).SetString(val); !ok {
+			return "error", "不是有效的数字"
+		}
+	case kindBoolean:
+		if _, err := parseBool(val); err != nil {
+			return "error", err.Error()
+		}
+	case kindJSON:
+		if err := checkJSON(val); err != nil {
+			return "error", err.Error()
+		}
+	}
+	return "", ""
+}
+
+// checkNumber 检查数值是否合法以及是否超出精度、小数位数和整数类型的范围
+func checkNumber(d dialect, c TableColumnInfo, val string) (string, string) {
+	r, ok := new(big.Rat).SetString(val)
+	if !ok {
+		return "error", "不是有效的数字"
+	}
+
+	// 按十进制展开后统计整数位数与小数位数
+	digits := r.FloatString(60)
+	if strings.HasPrefix(digits, "-") {
+		digits = digits[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	scale := c.Scale
+	if scale < 0 {
+		scale = 0
+		if c.kind() == kindDecimal {
+			// 未指定小数位数的 NUMBER 可以保存任意小数
+			scale = len(fracPart)
+		}
+	}
+
+	if c.Precision > 0 && len(intPart) > c.Precision-scale {
+		return "error", fmt.Sprintf("整数部分 %d 位超过精度 (%d,%d) 允许的 %d 位", len(intPart), c.Precision, scale, c.Precision-scale)
+	}
+
+	if c.kind() == kindInteger {
+		if min, max, ok := d.integerRange(c); ok {
+			i := new(big.Int).Quo(r.Num(), r.Denom())
+			if i.Cmp(min) < 0 || i.Cmp(max) > 0 {
+				return "error", fmt.Sprintf("超出 %s 的取值范围 [%s, %s]", c.ColumnType, min, max)
+			}
+		}
+	}
+
+	if len(fracPart) > scale {
+		if c.kind() == kindInteger {
+			if level, msg := d.integerFraction(); msg != "" {
+				return level, msg
+			}
+		}
+		return "warning", fmt.Sprintf("小数 %d 位超过 %d 位，将被舍入", len(fracPart), scale)
+	}
+	return "", ""
+}
+
+// integerRange 按 TINYINT~BIGINT 的位数返回整数类型的取值范围，unsignedTiny 表示 TINYINT 无符号(如 SQL Server 的 0~255)
+func integerRange(c TableColumnInfo, unsignedTiny bool) (*big.Int, *big.Int, bool) {
+	var bits uint
+	switch strings.ToUpper(c.DataType) {
+	case "TINYINT":
+		bits = 8
+	case "SMALLINT":
+		bits = 16
+	case "MEDIUMINT":
+		bits = 24
+	case "INT", "INTEGER":
+		bits = 32
+	case "BIGINT":
+		bits = 64
+	default:
+		return nil, nil, false
+	}
+	one := big.NewInt(1)
+	if strings.Contains(strings.ToLower(c.ColumnType), "unsigned") || (unsignedTiny && bits == 8) {
+		max := new(big.Int).Sub(new(big.Int).Lsh(one, bits), one)
+		return big.NewInt(0), max, true
+	}
+	half := new(big.Int).Lsh(one, bits-1)
+	return new(big.Int).Neg(half), new(big.Int).Sub(half, one), true
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckNumber(t *testing.T) {
+	number := func(precision, scale int) TableColumnInfo {
+		return TableColumnInfo{DataType: "NUMBER", ColumnType: "NUMBER", Precision: precision, Scale: scale}
+	}
+	integer := func(dataType, columnType string) TableColumnInfo {
+		return TableColumnInfo{DataType: dataType, ColumnType: columnType, Scale: 0}
+	}
+	tests := []struct {
+		dbType string
+		col    TableColumnInfo
+		val    string
+		level  string
+		msgHas string
+	}{
+		{dbType: "oracle", col: number(5, 2), val: "123.45"},
+		{dbType: "oracle", col: number(5, 2), val: "-00012.50"},
+		{dbType: "oracle", col: number(5, 2), val: "1e2"},
+		{dbType: "oracle", col: number(5, 2), val: "1234.5", level: "error", msgHas: "整数部分 4 位超过精度 (5,2) 允许的 3 位"},
+		{dbType: "oracle", col: number(5, 2), val: "1.234", level: "warning", msgHas: "小数 3 位超过 2 位，将被舍入"},
+		{dbType: "oracle", col: number(5, 2), val: "12,5", level: "error", msgHas: "不是有效的数字"},
+		{dbType: "oracle", col: number(0, -1), val: "12345678901234.123456"},
+		{dbType: "oracle", col: number(10, 0), val: "1.5", level: "warning", msgHas: "小数 1 位超过 0 位"},
+		{dbType: "mysql", col: integer("TINYINT", "tinyint"), val: "-128"},
+		{dbType: "mysql", col: integer("TINYINT", "tinyint"), val: "128", level: "error", msgHas: "超出 tinyint 的取值范围 [-128, 127]"},
+		{dbType: "mysql", col: integer("TINYINT", "tinyint unsigned"), val: "255"},
+		{dbType: "mysql", col: integer("TINYINT", "tinyint unsigned"), val: "-1", level: "error", msgHas: "[0, 255]"},
+		{dbType: "mysql", col: integer("BIGINT", "bigint"), val: "9223372036854775808", level: "error", msgHas: "超出 bigint 的取值范围"},
+		{dbType: "mysql", col: integer("INT", "int"), val: "1.5", level: "warning", msgHas: "小数部分将被舍入为整数"},
+		{dbType: "sqlserver", col: integer("TINYINT", "tinyint"), val: "255"},
+		{dbType: "sqlserver", col: integer("TINYINT", "tinyint"), val: "-1", level: "error", msgHas: "[0, 255]"},
+		{dbType: "sqlserver", col: integer("INT", "int"), val: "1.5", level: "error", msgHas: "整数列不能包含小数"},
+		{dbType: "postgres", col: integer("BIGINT", "bigint"), val: "9223372036854775807"},
+		{dbType: "postgres", col: integer("BIGINT", "bigint"), val: "9223372036854775808", level: "error", msgHas: "超出 bigint 的取值范围"},
+		{dbType: "postgres", col: integer("SMALLINT", "smallint"), val: "2.0"},
+		{dbType: "mysql", col: integer("SMALLINT", "smallint"), val: "2.0"},
+	}
+	for _, tt := range tests {
+		level, msg := checkNumber(dialects[tt.dbType], tt.col, tt.val)
+		if level != tt.level || !strings.Contains(msg, tt.msgHas) || (tt.msgHas == "" && msg != "") {
+			t.Errorf("%s %s %q = (%q, %q)，应为 (%q, 包含 %q)", tt.dbType, tt.col.ColumnType, tt.val, level, msg, tt.level, tt.msgHas)
+		}
+	}
+}
+
+func TestCheckValue(t *testing.T) {
+	varchar2 := TableColumnInfo{DataType: "VARCHAR2", CharUsed: "B", OctetLength: 6}
+	varcharChars := TableColumnInfo{DataType: "VARCHAR", CharUsed: "C", CharLength: 3, Nullable: true}
+	date := TableColumnInfo{DataType: "DATE", Nullable: true}
+	tests := []struct {
+		dbType   string
+		col      TableColumnInfo
+		val      string
+		truncate bool
+		level    string
+		msgHas   string
+	}{
+		{dbType: "oracle", col: varchar2, val: "", level: "error", msgHas: "不允许为空"},
+		{dbType: "mysql", col: varchar2, val: ""},
+		{dbType: "oracle", col: varchar2, val: "中文", level: ""},
+		{dbType: "oracle", col: varchar2, val: "中文字", level: "error", msgHas: "长度 9 字节超过上限 6"},
+		{dbType: "oracle", col: varchar2, val: "中文字", truncate: true, level: "warning", msgHas: "将被截断"},
+		{dbType: "mysql", col: varcharChars, val: "中文字"},
+		{dbType: "mysql", col: varcharChars, val: "abcd", level: "error", msgHas: "长度 4 字符超过上限 3"},
+		{dbType: "mysql", col: date, val: ""},
+		{dbType: "mysql", col: date, val: "2024-02-30x", level: "error", msgHas: "日期格式无法识别"},
+		{dbType: "mysql", col: date, val: "2024/02/29"},
+		{dbType: "mysql", col: TableColumnInfo{DataType: "DOUBLE", Nullable: true}, val: "1.5e300"},
+		{dbType: "mysql", col: TableColumnInfo{DataType: "DOUBLE", Nullable: true}, val: "N/A", level: "error", msgHas: "不是有效的数字"},
+	}
+	for _, tt := range tests {
+		level, msg := checkValue(dialects[tt.dbType], tt.col, tt.val, tt.truncate)
+		if level != tt.level || !strings.Contains(msg, tt.msgHas) || (tt.msgHas == "" && msg != "") {
+			t.Errorf("%s %s %q = (%q, %q)，应为 (%q, 包含 %q)", tt.dbType, tt.col.DataType, tt.val, level, msg, tt.level, tt.msgHas)
+		}
+	}
+}